@@ -0,0 +1,146 @@
+package gohash
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// bencoding of BitTorrent, see https://www.bittorrent.org/beps/bep_0003.html
+
+// bencode encodes `v`, which is an int, int64, string, []byte, []interface{}
+// or map[string]interface{}. Dictionary keys are sorted
+func bencode(v interface{}) []byte {
+	var buf bytes.Buffer
+	bencodeTo(&buf, v)
+	return buf.Bytes()
+}
+
+func bencodeTo(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case int:
+		bencodeTo(buf, int64(v))
+	case int64:
+		buf.WriteString("i" + strconv.FormatInt(v, 10) + "e")
+	case string:
+		buf.WriteString(strconv.Itoa(len(v)) + ":" + v)
+	case []byte:
+		bencodeTo(buf, string(v))
+	case []interface{}:
+		buf.WriteByte('l')
+		for _, e := range v {
+			bencodeTo(buf, e)
+		}
+		buf.WriteByte('e')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('d')
+		for _, k := range keys {
+			bencodeTo(buf, k)
+			bencodeTo(buf, v[k])
+		}
+		buf.WriteByte('e')
+	default:
+		panic(fmt.Sprintf("bencode: unsupported type %T", v))
+	}
+}
+
+// bdecoder decodes into int64, string, []interface{} and map[string]interface{}
+type bdecoder struct {
+	data []byte
+	pos  int
+	// the raw bytes of the value of the top level "info" key
+	info []byte
+}
+
+// bdecode decodes `data`, returning the value and the raw bytes of the "info" dictionary if any
+func bdecode(data []byte) (interface{}, []byte, error) {
+	d := &bdecoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.pos != len(data) {
+		return nil, nil, fmt.Errorf("bdecode: trailing data at %d", d.pos)
+	}
+	return v, d.info, nil
+}
+
+func (d *bdecoder) decode(depth int) (interface{}, error) {
+	if d.pos >= len(d.data) {
+		return nil, fmt.Errorf("bdecode: unexpected end of data")
+	}
+	switch c := d.data[d.pos]; {
+	case c == 'i':
+		end := bytes.IndexByte(d.data[d.pos:], 'e')
+		if end == -1 {
+			return nil, fmt.Errorf("bdecode: unterminated integer at %d", d.pos)
+		}
+		n, err := strconv.ParseInt(string(d.data[d.pos+1:d.pos+end]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bdecode: invalid integer at %d", d.pos)
+		}
+		d.pos += end + 1
+		return n, nil
+	case c >= '0' && c <= '9':
+		colon := bytes.IndexByte(d.data[d.pos:], ':')
+		if colon == -1 {
+			return nil, fmt.Errorf("bdecode: invalid string at %d", d.pos)
+		}
+		n, err := strconv.Atoi(string(d.data[d.pos : d.pos+colon]))
+		start := d.pos + colon + 1
+		if err != nil || n < 0 || n > len(d.data)-start {
+			return nil, fmt.Errorf("bdecode: invalid string length at %d", d.pos)
+		}
+		d.pos = start + n
+		return string(d.data[start:d.pos]), nil
+	case c == 'l':
+		d.pos++
+		list := []interface{}{}
+		for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		if d.pos >= len(d.data) {
+			return nil, fmt.Errorf("bdecode: unterminated list")
+		}
+		d.pos++
+		return list, nil
+	case c == 'd':
+		d.pos++
+		dict := map[string]interface{}{}
+		for d.pos < len(d.data) && d.data[d.pos] != 'e' {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("bdecode: dictionary key is not a string at %d", d.pos)
+			}
+			start := d.pos
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if depth == 0 && key == "info" {
+				d.info = d.data[start:d.pos]
+			}
+			dict[key] = v
+		}
+		if d.pos >= len(d.data) {
+			return nil, fmt.Errorf("bdecode: unterminated dictionary")
+		}
+		d.pos++
+		return dict, nil
+	}
+	return nil, fmt.Errorf("bdecode: invalid data at %d", d.pos)
+}
@@ -0,0 +1,36 @@
+package gohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBencode(t *testing.T) {
+	v := map[string]interface{}{
+		"spam": []interface{}{"a", 42, int64(-3)},
+		"cow":  []byte("moo"),
+		"":     map[string]interface{}{},
+	}
+	assert.Equal(t, "d0:de3:cow3:moo4:spaml1:ai42ei-3eee", string(bencode(v)))
+}
+
+func TestBdecode(t *testing.T) {
+	v, info, err := bdecode([]byte("d8:announce3:url4:infod4:name3:fox6:lengthi9eee"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "d4:name3:fox6:lengthi9ee", string(info))
+	assert.Equal(t, map[string]interface{}{
+		"announce": "url",
+		"info":     map[string]interface{}{"name": "fox", "length": int64(9)},
+	}, v)
+
+	v, info, err = bdecode([]byte("l4:infoi1ee"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []byte(nil), info)
+	assert.Equal(t, []interface{}{"info", int64(1)}, v)
+
+	for _, s := range []string{"", "i12", "ixe", "5:abc", "-1:", "d1:ae", "di1ei2ee", "l", "i1ei2e", "x"} {
+		_, _, err := bdecode([]byte(s))
+		assert.NotEqual(t, nil, err, s)
+	}
+}
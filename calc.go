@@ -1,19 +1,20 @@
 package gohash
 
 import (
-	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
-	"encoding/binary"
+	"crypto/subtle"
+	"encoding"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"hash/adler32"
-	"hash/crc32"
-	"hash/crc64"
 	"hash/fnv"
 	"io"
-	"sort"
+	"sync"
 
 	"github.com/cespare/xxhash"
 	"github.com/cxmcc/tiger"
@@ -21,26 +22,27 @@ import (
 	"github.com/dchest/blake2b"
 	"github.com/dchest/blake2s"
 	"github.com/dchest/blake512"
-	"github.com/dchest/siphash"
 	"github.com/dchest/skein"
-	"github.com/howeyc/crc16"
+	"github.com/ebfe/keccak"
+	"github.com/emmansun/gmsm/sm3"
 	"github.com/htruong/go-md2"
 	"github.com/jzelinskie/whirlpool"
-	"github.com/martinlindhe/crc24"
 	"github.com/martinlindhe/gogost/gost28147"
 	"github.com/martinlindhe/gogost/gost34112012256"
 	"github.com/martinlindhe/gogost/gost34112012512"
 	"github.com/martinlindhe/gogost/gost341194"
-	"github.com/mewpkg/hashutil/crc8"
+	"github.com/zeebo/blake3"
 	"golang.org/x/crypto/md4"
 	"golang.org/x/crypto/ripemd160"
 	"golang.org/x/crypto/sha3"
-	"lukechampine.com/blake3"
 )
 
 // Calculator is used to calculate hash of input cleartext
 type Calculator struct {
-	reader io.Reader
+	reader  io.Reader
+	key     []byte
+	hmacKey []byte
+	state   []byte
 }
 
 // NewCalculator creates a new Calculator
@@ -50,594 +52,485 @@ func NewCalculator(reader io.Reader) *Calculator {
 	}
 }
 
-var (
-	algos = map[string]int{
-		// name, key size in bits
-		"adler32":           32,
-		"blake224":          224,
-		"blake256":          256,
-		"blake384":          384,
-		"blake512":          512,
-		"blake2b-256":       256,
-		"blake2b-512":       512,
-		"blake2s-256":       256,
-		"blake3":            256,
-		"crc8-atm":          8,
-		"crc16-ccitt":       16,
-		"crc16-ccitt-false": 16,
-		"crc16-ibm":         16,
-		"crc16-scsi":        16,
-		"crc24-openpgp":     24,
-		"crc32-ieee":        32,
-		"crc32-castagnoli":  32,
-		"crc32-koopman":     32,
-		"crc64-iso":         64,
-		"crc64-ecma":        64,
-		"fnv1-32":           32,
-		"fnv1a-32":          32,
-		"fnv1-64":           64,
-		"fnv1a-64":          64,
-		"xxh64":             64,
-		"gost94":            256,
-		"gost94-cryptopro":  256,
-		"md2":               128,
-		"md4":               128,
-		"md5":               128,
-		"ripemd160":         160,
-		"sha1":              160,
-		"sha224":            224,
-		"sha256":            256,
-		"sha384":            384,
-		"sha512":            512,
-		"sha512-224":        224,
-		"sha512-256":        256,
-		"sha3-224":          224,
-		"sha3-256":          256,
-		"sha3-384":          384,
-		"sha3-512":          512,
-		"shake128-256":      256,
-		"shake256-512":      512,
-		"siphash-2-4":       64,
-		"skein512-256":      256,
-		"skein512-512":      512,
-		"streebog-256":      256,
-		"streebog-512":      512,
-		"tiger192":          192,
-		"whirlpool":         512,
-	}
-
-	hashers = map[string]func(io.Reader) ([]byte, error){
-		"adler32":           adler32Sum,
-		"blake224":          blake224Sum,
-		"blake256":          blake256Sum,
-		"blake384":          blake384Sum,
-		"blake512":          blake512Sum,
-		"blake2b-256":       blake2b256Sum,
-		"blake2b-512":       blake2b512Sum,
-		"blake2s-256":       blake2s256Sum,
-		"blake3":            blake3Sum,
-		"crc8-atm":          crc8AtmSum,
-		"crc16-ccitt":       crc16CcittSum,
-		"crc16-ccitt-false": crc16CcittFalseSum,
-		"crc16-ibm":         crc16IbmSum,
-		"crc16-scsi":        crc16ScsiSum,
-		"crc24-openpgp":     crc24OpenPGPSum,
-		"crc32-ieee":        crc32IEEESum,
-		"crc32-castagnoli":  crc32CastagnoliSum,
-		"crc32-koopman":     crc32KoopmanSum,
-		"crc64-iso":         crc64ISOSum,
-		"crc64-ecma":        crc64ECMASum,
-		"fnv1-32":           fnv1_32Sum,
-		"fnv1a-32":          fnv1a32Sum,
-		"fnv1-64":           fnv1_64Sum,
-		"fnv1a-64":          fnv1a64Sum,
-		"xxh64":             xxh64Sum,
-		"gost94":            gost94Sum,
-		"gost94-cryptopro":  gost94CryptoproSum,
-		"md2":               md2Sum,
-		"md4":               md4Sum,
-		"md5":               md5Sum,
-		"ripemd160":         ripemd160Sum,
-		"sha1":              sha1Sum,
-		"sha224":            sha224Sum,
-		"sha256":            sha256Sum,
-		"sha384":            sha384Sum,
-		"sha512":            sha512Sum,
-		"sha512-224":        sha512_224Sum,
-		"sha512-256":        sha512_256Sum,
-		"sha3-224":          sha3_224Sum,
-		"sha3-256":          sha3_256Sum,
-		"sha3-384":          sha3_384Sum,
-		"sha3-512":          sha3_512Sum,
-		"shake128-256":      shake128_256Sum,
-		"shake256-512":      shake256_512Sum,
-		"siphash-2-4":       siphash2_4Sum,
-		"skein512-256":      skein512_256Sum,
-		"skein512-512":      skein512_512Sum,
-		"streebog-256":      streebog256Sum,
-		"streebog-512":      streebog512Sum,
-		"tiger192":          tiger192Sum,
-		"whirlpool":         whirlpoolSum,
-	}
+const (
+	// size of the chunks read by SumMany
+	sumManyChunkSize = 1024 * 1024
 )
 
-// Sum returns the checksum
-func (c *Calculator) Sum(algo string) ([]byte, error) {
-	algo = resolveAlgoAliases(algo)
-	if checksum, ok := hashers[algo]; ok {
-		return checksum(c.reader)
-	}
-	return nil, fmt.Errorf("%s", "FATAL: unknown algo "+algo)
-}
-
-// AvailableHashes returns the available hash id's
-func AvailableHashes() []string {
-
-	res := []string{}
-
-	for key := range hashers {
-		res = append(res, key)
-	}
-
-	sort.Strings(res)
-	return res
-}
-
-func resolveAlgoAliases(s string) string {
-
-	if s == "crc32" {
-		return "crc32-ieee"
-	}
-	if s == "crc32c" {
-		return "crc32-castagnoli"
-	}
-	if s == "crc32k" {
-		return "crc32-koopman"
-	}
-
-	// "skein256" is used by sphsum
-	if s == "skein256" {
-		return "skein512-256"
-	}
-
-	// "skein512" is used by sphsum
-	if s == "skein512" {
-		return "skein512-256"
-	}
-
-	// "tiger" is used by rhash, sphsum
-	if s == "tiger" {
-		return "tiger192"
-	}
-
+// builtinHashes are the hash algorithms registered by default
+var builtinHashes = []HashAlgorithm{
+	{Name: "adler32", Bits: 32, Family: "adler", New: newAdler32},
+	{Name: "blake224", Bits: 224, Family: "blake", New: blake256.New224},
+	{Name: "blake256", Bits: 256, Family: "blake", New: blake256.New},
+	{Name: "blake384", Bits: 384, Family: "blake", New: blake512.New384},
+	{Name: "blake512", Bits: 512, Family: "blake", New: blake512.New},
+	{Name: "blake2b-256", Bits: 256, Family: "blake2", New: blake2b.New256, NewWithParams: newBlake2bWithParams(32)},
+	{Name: "blake2b-512", Aliases: []string{"blake2b"}, Bits: 512, Family: "blake2", VariableSize: true, New: blake2b.New512, NewWithParams: newBlake2bWithParams(64)},
+	{Name: "blake2s-256", Aliases: []string{"blake2s"}, Bits: 256, Family: "blake2", VariableSize: true, New: blake2s.New256, NewWithParams: newBlake2sWithParams(32)},
+	{Name: "blake3", Bits: 256, Family: "blake3", VariableSize: true, New: newBlake3, NewWithParams: newBlake3WithParams},
+	{Name: "fnv1-32", Bits: 32, Family: "fnv", New: func() hash.Hash { return fnv.New32() }},
+	{Name: "fnv1a-32", Bits: 32, Family: "fnv", New: func() hash.Hash { return fnv.New32a() }},
+	{Name: "fnv1-64", Bits: 64, Family: "fnv", New: func() hash.Hash { return fnv.New64() }},
+	{Name: "fnv1a-64", Bits: 64, Family: "fnv", New: func() hash.Hash { return fnv.New64a() }},
+	{Name: "xxh32", Bits: 32, Family: "xxhash", New: newXXH32, NewWithParams: newXXH32WithParams},
+	{Name: "xxh64", Bits: 64, Family: "xxhash", New: func() hash.Hash { return xxhash.New() }, NewWithParams: newXXH64WithParams},
+	{Name: "xxh3-64", Aliases: []string{"xxh3"}, Bits: 64, Family: "xxhash", New: func() hash.Hash { return newXXH3(8, 0) }, NewWithParams: newXXH3WithParams(8)},
+	{Name: "xxh3-128", Bits: 128, Family: "xxhash", New: func() hash.Hash { return newXXH3(16, 0) }, NewWithParams: newXXH3WithParams(16)},
+	{Name: "murmur3-32", Aliases: []string{"murmur3"}, Bits: 32, Family: "murmur3", New: newMurmur3(4), NewWithParams: newMurmur3WithParams(4)},
+	{Name: "murmur3-128", Bits: 128, Family: "murmur3", New: newMurmur3(16), NewWithParams: newMurmur3WithParams(16)},
+	{Name: "city32", Bits: 32, Family: "city", New: newCity32},
+	{Name: "city64", Bits: 64, Family: "city", New: func() hash.Hash { return newCity64(0) }, NewWithParams: newCity64WithParams},
+	{Name: "city128", Bits: 128, Family: "city", New: newCity128},
+	{Name: "farm32", Bits: 32, Family: "farm", New: newFarm32},
+	{Name: "farm64", Bits: 64, Family: "farm", New: newFarm64},
+	{Name: "farm128", Bits: 128, Family: "farm", New: newFarm128},
+	{Name: "highwayhash-64", Bits: 64, Family: "highwayhash", New: newHighway(8), NewWithParams: newHighwayWithParams(8)},
+	{Name: "highwayhash-128", Bits: 128, Family: "highwayhash", New: newHighway(16), NewWithParams: newHighwayWithParams(16)},
+	{Name: "highwayhash-256", Aliases: []string{"highwayhash"}, Bits: 256, Family: "highwayhash", New: newHighway(32), NewWithParams: newHighwayWithParams(32)},
+	{Name: "wyhash", Bits: 64, Family: "wyhash", New: func() hash.Hash { return newWyhash(0) }, NewWithParams: newWyhashWithParams},
 	// "gost" is used by rhash
-	if s == "gost" {
-		return "gost94"
-	}
-
+	{Name: "gost94", Aliases: []string{"gost"}, Bits: 256, Family: "gost", New: func() hash.Hash { return gost341194.New(gost341194.SboxDefault) }},
+	{Name: "gost94-cryptopro", Bits: 256, Family: "gost", New: func() hash.Hash { return gost341194.New(&gost28147.GostR3411_94_CryptoProParamSet) }},
+	{Name: "lsh-256-224", Bits: 224, Family: "lsh", New: func() hash.Hash { return newLSH256(28) }},
+	{Name: "lsh-256-256", Aliases: []string{"lsh-256"}, Bits: 256, Family: "lsh", New: func() hash.Hash { return newLSH256(32) }},
+	{Name: "lsh-512-224", Bits: 224, Family: "lsh", New: func() hash.Hash { return newLSH512(28) }},
+	{Name: "lsh-512-256", Bits: 256, Family: "lsh", New: func() hash.Hash { return newLSH512(32) }},
+	{Name: "lsh-512-384", Bits: 384, Family: "lsh", New: func() hash.Hash { return newLSH512(48) }},
+	{Name: "lsh-512-512", Aliases: []string{"lsh-512"}, Bits: 512, Family: "lsh", New: func() hash.Hash { return newLSH512(64) }},
+	{Name: "kupyna-256", Bits: 256, Family: "kupyna", New: func() hash.Hash { return newKupyna(32) }},
+	{Name: "kupyna-384", Bits: 384, Family: "kupyna", New: func() hash.Hash { return newKupyna(48) }},
+	{Name: "kupyna-512", Bits: 512, Family: "kupyna", New: func() hash.Hash { return newKupyna(64) }},
+	{Name: "md2", Bits: 128, Family: "md", New: md2.New},
+	{Name: "md4", Bits: 128, Family: "md", New: md4.New},
+	{Name: "md5", Bits: 128, Family: "md", New: md5.New},
+	{Name: "ripemd160", Bits: 160, Family: "ripemd", New: ripemd160.New},
+	{Name: "sha1", Bits: 160, Family: "sha1", New: sha1.New},
+	{Name: "sm3", Bits: 256, Family: "sm3", New: sm3.New},
+	{Name: "has160", Aliases: []string{"has-160"}, Bits: 160, Family: "has160", New: newHAS160},
+	{Name: "sha224", Bits: 224, Family: "sha2", New: sha256.New224},
+	{Name: "sha256", Bits: 256, Family: "sha2", New: sha256.New},
+	{Name: "sha384", Bits: 384, Family: "sha2", New: sha512.New384},
+	{Name: "sha512", Bits: 512, Family: "sha2", New: sha512.New},
+	{Name: "sha512-224", Bits: 224, Family: "sha2", New: sha512.New512_224},
+	{Name: "sha512-256", Bits: 256, Family: "sha2", New: sha512.New512_256},
+	{Name: "sha3-224", Bits: 224, Family: "sha3", New: sha3.New224},
+	{Name: "sha3-256", Bits: 256, Family: "sha3", New: sha3.New256},
+	{Name: "sha3-384", Bits: 384, Family: "sha3", New: sha3.New384},
+	{Name: "sha3-512", Bits: 512, Family: "sha3", New: sha3.New512},
+	// the original keccak submission, with the padding used by ethereum
+	{Name: "keccak224", Aliases: []string{"keccak-224"}, Bits: 224, Family: "keccak", New: keccak.New224},
+	{Name: "keccak256", Aliases: []string{"keccak-256"}, Bits: 256, Family: "keccak", New: keccak.New256},
+	{Name: "keccak384", Aliases: []string{"keccak-384"}, Bits: 384, Family: "keccak", New: keccak.New384},
+	{Name: "keccak512", Aliases: []string{"keccak-512"}, Bits: 512, Family: "keccak", New: keccak.New512},
+	{Name: "shake128-256", Aliases: []string{"shake128"}, Bits: 256, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newXOF(sha3.NewShake128(), 256/8) }, NewWithParams: newShakeWithParams(128)},
+	{Name: "shake256-512", Aliases: []string{"shake256"}, Bits: 512, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newXOF(sha3.NewShake256(), 512/8) }, NewWithParams: newShakeWithParams(256)},
+	{Name: "turboshake128-256", Aliases: []string{"turboshake128"}, Bits: 256, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newXOF(newTurboShake(128, turboShakeDomain), 256/8) }, NewWithParams: newTurboShakeWithParams(128)},
+	{Name: "turboshake256-512", Aliases: []string{"turboshake256"}, Bits: 512, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newXOF(newTurboShake(256, turboShakeDomain), 512/8) }, NewWithParams: newTurboShakeWithParams(256)},
+	{Name: "k12", Aliases: []string{"kangarootwelve", "kt128"}, Bits: 256, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newXOF(newKangarooTwelve(nil), 256/8) }, NewWithParams: newKangarooTwelveWithParams},
+	// NIST SP 800-185 functions. cshake without name or customization string is shake,
+	// so it is not a candidate for other digest sizes
+	{Name: "cshake128", Bits: 256, Family: "sha3", New: func() hash.Hash { return newXOF(sha3.NewCShake128(nil, nil), 256/8) }, NewWithParams: newShakeWithParams(128)},
+	{Name: "cshake256", Bits: 512, Family: "sha3", New: func() hash.Hash { return newXOF(sha3.NewCShake256(nil, nil), 512/8) }, NewWithParams: newShakeWithParams(256)},
+	{Name: "kmac128", Bits: 256, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newKMAC(128, nil, nil, 256/8) }, NewWithParams: newKMACWithParams(128)},
+	{Name: "kmac256", Bits: 512, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newKMAC(256, nil, nil, 512/8) }, NewWithParams: newKMACWithParams(256)},
+	{Name: "parallelhash128", Bits: 256, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newParallelHash(128, nil, 8192, 256/8) }, NewWithParams: newParallelHashWithParams(128)},
+	{Name: "parallelhash256", Bits: 512, Family: "sha3", VariableSize: true, New: func() hash.Hash { return newParallelHash(256, nil, 8192, 512/8) }, NewWithParams: newParallelHashWithParams(256)},
+	{Name: "siphash-1-3", Bits: 64, Family: "siphash", New: newSiphash(1, 3, 8), NewWithParams: newSiphashWithParams(1, 3, 8)},
+	{Name: "siphash-1-3-128", Bits: 128, Family: "siphash", New: newSiphash(1, 3, 16), NewWithParams: newSiphashWithParams(1, 3, 16)},
+	{Name: "siphash-2-4", Bits: 64, Family: "siphash", New: newSiphash(2, 4, 8), NewWithParams: newSiphashWithParams(2, 4, 8)},
+	{Name: "siphash-2-4-128", Bits: 128, Family: "siphash", New: newSiphash(2, 4, 16), NewWithParams: newSiphashWithParams(2, 4, 16)},
+	// "skein256" and "skein512" are used by sphsum
+	{Name: "skein512-256", Aliases: []string{"skein256", "skein512"}, Bits: 256, Family: "skein", New: func() hash.Hash { return skein.NewHash(32) }},
+	{Name: "skein512-512", Bits: 512, Family: "skein", New: func() hash.Hash { return skein.NewHash(64) }},
 	// streebog is sometimes referred to as GOST-2012
-	if s == "gost2012-256" {
-		return "streebog-256"
-	}
-	if s == "gost2012-512" {
-		return "streebog-512"
-	}
-
-	return s
-}
-
-func adler32Sum(r io.Reader) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	_, _ = buf.ReadFrom(r)
-	i := adler32.Checksum(buf.Bytes())
-	bs := make([]byte, 4)
-	binary.BigEndian.PutUint32(bs, i)
-	return bs, nil
+	{Name: "streebog-256", Aliases: []string{"gost2012-256"}, Bits: 256, Family: "streebog", New: func() hash.Hash { return gost34112012256.New() }},
+	{Name: "streebog-512", Aliases: []string{"gost2012-512"}, Bits: 512, Family: "streebog", New: func() hash.Hash { return gost34112012512.New() }},
+	// "tiger" is used by rhash, sphsum
+	{Name: "tiger192", Aliases: []string{"tiger"}, Bits: 192, Family: "tiger", New: tiger.New},
+	{Name: "whirlpool", Bits: 512, Family: "whirlpool", New: whirlpool.New},
 }
 
-func blake224Sum(r io.Reader) ([]byte, error) {
-	h := blake256.New224()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+func init() {
+	for _, algo := range builtinHashes {
+		mustRegisterHash(algo)
 	}
-	return h.Sum(nil), nil
 }
 
-func blake256Sum(r io.Reader) ([]byte, error) {
-	h := blake256.New()
-	if _, err := io.Copy(h, r); err != nil {
+// NewHash returns a new hash.Hash computing the checksum of `algo`.
+// Parameters may be given as in "blake2b:output=20,salt=00ff"
+func NewHash(algo string) (hash.Hash, error) {
+	name, params, err := parseAlgoSpec(algo)
+	if err != nil {
 		return nil, err
 	}
-	return h.Sum(nil), nil
+	return newHashWithParams(name, params)
 }
 
-func blake384Sum(r io.Reader) ([]byte, error) {
-	h := blake512.New384()
-	if _, err := io.Copy(h, r); err != nil {
+// NewKeyedHash returns a new hash.Hash computing the keyed algorithm `algo` using `key`
+func NewKeyedHash(algo string, key []byte) (hash.Hash, error) {
+	name, params, err := parseAlgoSpec(algo)
+	if err != nil {
 		return nil, err
 	}
-	return h.Sum(nil), nil
-}
-
-func blake512Sum(r io.Reader) ([]byte, error) {
-	h := blake512.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if _, ok := params["key"]; ok {
+		return nil, fmt.Errorf("%s: key given twice", name)
 	}
-	return h.Sum(nil), nil
+	params["key"] = hex.EncodeToString(key)
+	return newHashWithParams(name, params)
 }
 
-func blake2b256Sum(r io.Reader) ([]byte, error) {
-	h := blake2b.New256()
-	if _, err := io.Copy(h, r); err != nil {
+// NewHMAC returns a new hash.Hash computing the HMAC of `algo` using `key`
+func NewHMAC(algo string, key []byte) (hash.Hash, error) {
+	name, params, err := parseAlgoSpec(algo)
+	if err != nil {
 		return nil, err
 	}
-	return h.Sum(nil), nil
-}
-
-func blake2b512Sum(r io.Reader) ([]byte, error) {
-	h := blake2b.New512()
-	if _, err := io.Copy(h, r); err != nil {
+	h, err := newHashWithParams(name, params)
+	if err != nil {
 		return nil, err
 	}
-	return h.Sum(nil), nil
-}
-
-func blake2s256Sum(r io.Reader) ([]byte, error) {
-	h := blake2s.New256()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if h.BlockSize() <= 1 {
+		return nil, fmt.Errorf("%s has no block size, HMAC is not supported", resolveAlgoAliases(name))
 	}
-	return h.Sum(nil), nil
+	return hmac.New(func() hash.Hash {
+		h, _ := newHashWithParams(name, params)
+		return h
+	}, key), nil
 }
 
-func blake3Sum(r io.Reader) ([]byte, error) {
-	h := blake3.New(256/8, nil)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+func newHashWithParams(name string, params HashParams) (hash.Hash, error) {
+	algo, ok := lookupHash(name)
+	if !ok {
+		return nil, fmt.Errorf("%s", "FATAL: unknown algo "+resolveAlgoAliases(name))
 	}
-	return h.Sum(nil), nil
-}
-
-func crc8AtmSum(r io.Reader) ([]byte, error) {
-	h := crc8.NewATM()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if len(params) == 0 {
+		return algo.New(), nil
 	}
-	return h.Sum(nil), nil
-}
-
-func crc16CcittSum(r io.Reader) ([]byte, error) {
-	h := crc16.New(crc16.CCITTTable)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if algo.NewWithParams == nil {
+		return nil, fmt.Errorf("%s does not take parameters", algo.Name)
 	}
-	return h.Sum(nil), nil
+	h, err := algo.NewWithParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", algo.Name, err)
+	}
+	return h, nil
 }
 
-func crc16CcittFalseSum(r io.Reader) ([]byte, error) {
-	// NOTE: can not set crc16.digest.crc to 0xFFFF with current crc16 api so cannot use io.Copy() like in the others
-	buf := new(bytes.Buffer)
-	_, _ = buf.ReadFrom(r)
-	i := crc16.ChecksumCCITTFalse(buf.Bytes())
-	bs := make([]byte, 2)
-	binary.BigEndian.PutUint16(bs, i)
-	return bs, nil
+// HMACKey sets the key, making the Calculator compute HMAC:s instead of plain checksums
+func (c *Calculator) HMACKey(key []byte) {
+	c.hmacKey = key
 }
 
-func crc16IbmSum(r io.Reader) ([]byte, error) {
-	h := crc16.New(crc16.IBMTable)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+// Key sets the key used by keyed algorithms, such as siphash
+func (c *Calculator) Key(key []byte) {
+	c.key = key
 }
 
-func crc16ScsiSum(r io.Reader) ([]byte, error) {
-	h := crc16.New(crc16.SCSITable)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+// ResumeState sets a state saved by SumState, making the Calculator continue
+// hashing from it. The input should be the data following what was hashed
+func (c *Calculator) ResumeState(state []byte) {
+	c.state = state
 }
 
-func crc24OpenPGPSum(r io.Reader) ([]byte, error) {
-	h := crc24.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// returns the hash of `algo` and the number of bytes hashed by a resumed state
+func (c *Calculator) newHash(algo string) (hash.Hash, uint64, error) {
+	if c.key != nil && c.hmacKey != nil {
+		return nil, 0, fmt.Errorf("key and HMAC key dont mix")
 	}
-	return h.Sum(nil), nil
-}
-
-func crc32IEEESum(r io.Reader) ([]byte, error) {
-	h := crc32.New(crc32.MakeTable(crc32.IEEE))
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	var h hash.Hash
+	var err error
+	switch {
+	case c.key != nil:
+		h, err = NewKeyedHash(algo, c.key)
+	case c.hmacKey != nil:
+		h, err = NewHMAC(algo, c.hmacKey)
+	default:
+		h, err = NewHash(algo)
 	}
-	return h.Sum(nil), nil
-}
-
-func crc32CastagnoliSum(r io.Reader) ([]byte, error) {
-	h := crc32.New(crc32.MakeTable(crc32.Castagnoli))
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if err != nil || c.state == nil {
+		return h, 0, err
 	}
-	return h.Sum(nil), nil
-}
-
-func crc32KoopmanSum(r io.Reader) ([]byte, error) {
-	h := crc32.New(crc32.MakeTable(crc32.Koopman))
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	length, err := unmarshalState(algo, h, c.state, c.key != nil)
+	if err != nil {
+		return nil, 0, err
 	}
-	return h.Sum(nil), nil
+	return h, length, nil
 }
 
-func crc64ISOSum(r io.Reader) ([]byte, error) {
-	h := crc64.New(crc64.MakeTable(crc64.ISO))
-	if _, err := io.Copy(h, r); err != nil {
+// Sum returns the checksum
+func (c *Calculator) Sum(algo string) ([]byte, error) {
+	h, _, err := c.newHash(algo)
+	if err != nil {
 		return nil, err
 	}
-	return h.Sum(nil), nil
-}
-
-func crc64ECMASum(r io.Reader) ([]byte, error) {
-	h := crc64.New(crc64.MakeTable(crc64.ECMA))
-	if _, err := io.Copy(h, r); err != nil {
+	if _, err := io.Copy(h, c.reader); err != nil {
 		return nil, err
 	}
-	return h.Sum(nil), nil
-}
-
-func fnv1_32Sum(r io.Reader) ([]byte, error) {
-	h := fnv.New32()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if err := hashErr(h); err != nil {
+		return nil, fmt.Errorf("%s: %v", algo, err)
 	}
 	return h.Sum(nil), nil
 }
 
-func fnv1a32Sum(r io.Reader) ([]byte, error) {
-	h := fnv.New32a()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// hashErr returns the error of a one-shot function on too large input, as reported
+// by Err since hash.Hash.Write never returns an error
+func hashErr(h hash.Hash) error {
+	if e, ok := h.(interface{ Err() error }); ok {
+		return e.Err()
 	}
-	return h.Sum(nil), nil
+	return nil
 }
 
-func fnv1_64Sum(r io.Reader) ([]byte, error) {
-	h := fnv.New64()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// Verify returns true if the checksum of `algo` matches `expected`, compared in constant time
+func (c *Calculator) Verify(algo string, expected []byte) (bool, error) {
+	sum, err := c.Sum(algo)
+	if err != nil {
+		return false, err
 	}
-	return h.Sum(nil), nil
+	return subtle.ConstantTimeCompare(sum, expected) == 1, nil
 }
 
-func fnv1a64Sum(r io.Reader) ([]byte, error) {
-	h := fnv.New64a()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// Digest returns the checksum of `algo` as a digest of the OCI image specification,
+// such as "sha256:<hex>"
+func (c *Calculator) Digest(algo string) (string, error) {
+	sum, err := c.Sum(algo)
+	if err != nil {
+		return "", err
 	}
-	return h.Sum(nil), nil
+	return algo + ":" + hex.EncodeToString(sum), nil
 }
 
-func gost94Sum(r io.Reader) ([]byte, error) {
-	h := gost341194.New(gost341194.SboxDefault)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// VerifyDigest returns true if the checksum matches `digest`, such as "sha256:<hex>"
+func (c *Calculator) VerifyDigest(digest string) (bool, error) {
+	algo, expected, err := ParseDigest(digest)
+	if err != nil {
+		return false, err
 	}
-	return h.Sum(nil), nil
+	return c.Verify(algo, expected)
 }
 
-func gost94CryptoproSum(r io.Reader) ([]byte, error) {
-	h := gost341194.New(&gost28147.GostR3411_94_CryptoProParamSet)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// SumState returns the checksum and the state of the hash after reading the input,
+// for hashing of appended data to be continued with ResumeState. Keyed hashes are not
+// supported, as their state reveals the key
+func (c *Calculator) SumState(algo string) ([]byte, []byte, error) {
+	h, length, err := c.newHash(algo)
+	if err != nil {
+		return nil, nil, err
 	}
-	return h.Sum(nil), nil
-}
-
-func streebog256Sum(r io.Reader) ([]byte, error) {
-	h := gost34112012256.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	if _, ok := h.(encoding.BinaryMarshaler); !ok {
+		return nil, nil, fmt.Errorf("%s does not support saving state", algo)
 	}
-	return h.Sum(nil), nil
-}
-
-func streebog512Sum(r io.Reader) ([]byte, error) {
-	h := gost34112012512.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	n, err := io.Copy(h, c.reader)
+	if err != nil {
+		return nil, nil, err
 	}
-	return h.Sum(nil), nil
+	if err := hashErr(h); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", algo, err)
+	}
+	state, err := marshalState(algo, h, length+uint64(n), c.key != nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.Sum(nil), state, nil
 }
 
-func md2Sum(r io.Reader) (digest []byte, err error) {
-	h := md2.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+// SumMany returns the checksums for all `ids`, keyed by id. The input is only
+// read once, each chunk is fed to all hashers concurrently
+func (c *Calculator) SumMany(ids []string) (map[string][]byte, error) {
+	return c.sumMany(c.reader, ids)
 }
 
-func md4Sum(r io.Reader) (digest []byte, err error) {
-	h := md4.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+func (c *Calculator) sumMany(reader io.Reader, ids []string) (map[string][]byte, error) {
+
+	hs := make([]hash.Hash, len(ids))
+	for i, id := range ids {
+		h, _, err := c.newHash(id)
+		if err != nil {
+			return nil, err
+		}
+		hs[i] = h
 	}
-	return h.Sum(nil), nil
-}
 
-func md5Sum(r io.Reader) (digest []byte, err error) {
-	h := md5.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	var chunkDone, hashersDone sync.WaitGroup
+	feeds := make([]chan []byte, len(hs))
+	for i, h := range hs {
+		feeds[i] = make(chan []byte)
+		hashersDone.Add(1)
+		go func(h hash.Hash, feed chan []byte) {
+			defer hashersDone.Done()
+			for chunk := range feed {
+				// hash.Hash.Write never returns an error
+				_, _ = h.Write(chunk)
+				chunkDone.Done()
+			}
+		}(h, feeds[i])
 	}
-	return h.Sum(nil), nil
-}
 
-func ripemd160Sum(r io.Reader) (digest []byte, err error) {
-	h := ripemd160.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	var readErr error
+	buf := make([]byte, sumManyChunkSize)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			chunkDone.Add(len(feeds))
+			for _, feed := range feeds {
+				feed <- buf[:n]
+			}
+			chunkDone.Wait()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
 	}
-	return h.Sum(nil), nil
+	for _, feed := range feeds {
+		close(feed)
+	}
+	hashersDone.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	for i, h := range hs {
+		if err := hashErr(h); err != nil {
+			return nil, fmt.Errorf("%s: %v", ids[i], err)
+		}
+	}
+	res := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		res[id] = hs[i].Sum(nil)
+	}
+	return res, nil
 }
 
-func sha1Sum(r io.Reader) (digest []byte, err error) {
-	h := sha1.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+// AvailableHashes returns the available hash id's
+func AvailableHashes() []string {
+
+	res := []string{}
+
+	for _, algo := range registeredHashes() {
+		res = append(res, algo.Name)
 	}
-	return h.Sum(digest[:0]), nil
+
+	return res
 }
 
-func sha224Sum(r io.Reader) (digest []byte, err error) {
-	h := sha256.New224()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(digest[:0]), nil
+func newAdler32() hash.Hash {
+	return adler32.New()
 }
 
-func sha256Sum(r io.Reader) (digest []byte, err error) {
-	h := sha256.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+func newBlake2bWithParams(size int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		c, err := blake2Config(p, size)
+		if err != nil {
+			return nil, err
+		}
+		return blake2b.New(&blake2b.Config{Size: c.Size, Key: c.Key, Salt: c.Salt, Person: c.Person})
 	}
-	return h.Sum(digest[:0]), nil
 }
 
-func sha384Sum(r io.Reader) (digest []byte, err error) {
-	h := sha512.New384()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+func newBlake2sWithParams(size int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		c, err := blake2Config(p, size)
+		if err != nil {
+			return nil, err
+		}
+		return blake2s.New(&blake2s.Config{Size: c.Size, Key: c.Key, Salt: c.Salt, Person: c.Person})
 	}
-	return h.Sum(digest[:0]), nil
 }
 
-func sha512Sum(r io.Reader) (digest []byte, err error) {
-	h := sha512.New()
-	if _, err := io.Copy(h, r); err != nil {
+// parses the output size, key, salt and personalization parameters of BLAKE2
+func blake2Config(p HashParams, size int) (*blake2b.Config, error) {
+	if err := p.allow("output", "key", "salt", "person"); err != nil {
 		return nil, err
 	}
-	return h.Sum(digest[:0]), nil
-}
-
-func sha512_224Sum(r io.Reader) (digest []byte, err error) {
-	h := sha512.New512_224()
-	if _, err := io.Copy(h, r); err != nil {
+	output, err := p.output(size)
+	if err != nil {
 		return nil, err
 	}
-	return h.Sum(digest[:0]), nil
-}
-
-func sha512_256Sum(r io.Reader) (digest []byte, err error) {
-	h := sha512.New512_256()
-	if _, err := io.Copy(h, r); err != nil {
+	if output > 255 {
+		return nil, fmt.Errorf("invalid output size %d", output)
+	}
+	c := &blake2b.Config{Size: uint8(output)}
+	if c.Key, err = p.bytes("key"); err != nil {
 		return nil, err
 	}
-	return h.Sum(digest[:0]), nil
-}
-
-func sha3_224Sum(r io.Reader) (digest []byte, err error) {
-	h := sha3.New224()
-	if _, err := io.Copy(h, r); err != nil {
+	if c.Salt, err = p.bytes("salt"); err != nil {
 		return nil, err
 	}
-	return h.Sum(digest[:0]), nil
-}
-
-func sha3_256Sum(r io.Reader) (digest []byte, err error) {
-	h := sha3.New256()
-	if _, err := io.Copy(h, r); err != nil {
+	if c.Person, err = p.bytes("person"); err != nil {
 		return nil, err
 	}
-	return h.Sum(digest[:0]), nil
+	return c, nil
 }
 
-func sha3_384Sum(r io.Reader) (digest []byte, err error) {
-	h := sha3.New384()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(digest[:0]), nil
+func newBlake3() hash.Hash {
+	return blake3.New()
 }
 
-func sha3_512Sum(r io.Reader) (digest []byte, err error) {
-	h := sha3.New512()
-	if _, err := io.Copy(h, r); err != nil {
+// blake3 takes a key for keyed hashing, or a context string to derive keys,
+// and an output size in bytes
+func newBlake3WithParams(p HashParams) (hash.Hash, error) {
+	if err := p.allow("output", "key", "context"); err != nil {
 		return nil, err
 	}
-	return h.Sum(digest[:0]), nil
-}
-
-func shake128_256Sum(r io.Reader) ([]byte, error) {
-	res := make([]byte, 32) // 256 bits
-	h := sha3.NewShake128()
-	if _, err := io.Copy(h, r); err != nil {
+	output, err := p.output(32)
+	if err != nil {
 		return nil, err
 	}
-	_, _ = h.Read(res)
-	return res, nil
-}
-
-func shake256_512Sum(r io.Reader) ([]byte, error) {
-	res := make([]byte, 64) // 512 bits
-	h := sha3.NewShake256()
-	if _, err := io.Copy(h, r); err != nil {
+	key, err := p.bytes("key")
+	if err != nil {
 		return nil, err
 	}
-	_, _ = h.Read(res)
-	return res, nil
-}
+	context, hasContext := p["context"]
 
-func siphash2_4Sum(r io.Reader) ([]byte, error) {
-	key := make([]byte, 16) // NOTE using empty key
-	h := siphash.New(key)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
+	var h *blake3.Hasher
+	switch {
+	case key != nil && hasContext:
+		return nil, fmt.Errorf("key and context dont mix")
+	case key != nil:
+		if h, err = blake3.NewKeyed(key); err != nil {
+			return nil, fmt.Errorf("key must be 32 bytes, is %d", len(key))
+		}
+	case hasContext:
+		h = blake3.NewDeriveKey(context)
+	default:
+		h = blake3.New()
 	}
-	return h.Sum(nil), nil
+	return &blake3XOF{Hasher: h, size: output}, nil
 }
 
-func skein512_256Sum(r io.Reader) ([]byte, error) {
-	h := skein.NewHash(32)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+// xofHash is a hash.Hash reading a fixed size output from an extendable-output function
+type xofHash struct {
+	sha3.ShakeHash
+	size int
 }
 
-func skein512_512Sum(r io.Reader) ([]byte, error) {
-	h := skein.NewHash(64)
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+func newXOF(x sha3.ShakeHash, size int) *xofHash {
+	return &xofHash{ShakeHash: x, size: size}
 }
 
-func tiger192Sum(r io.Reader) ([]byte, error) {
-	h := tiger.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+func (h *xofHash) Sum(b []byte) []byte {
+	res := make([]byte, h.size)
+	// read from a copy, so more data can be written afterwards
+	_, _ = h.Clone().Read(res)
+	return append(b, res...)
 }
 
-func whirlpoolSum(r io.Reader) ([]byte, error) {
-	h := whirlpool.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+func (h *xofHash) Size() int { return h.size }
+
+// blake3XOF is a BLAKE3 hash.Hash with arbitrary output size
+type blake3XOF struct {
+	*blake3.Hasher
+	size int
 }
 
-func xxh64Sum(r io.Reader) ([]byte, error) {
-	h := xxhash.New()
-	if _, err := io.Copy(h, r); err != nil {
-		return nil, err
-	}
-	return h.Sum(nil), nil
+func (h *blake3XOF) Sum(b []byte) []byte {
+	res := make([]byte, h.size)
+	_, _ = h.Digest().Read(res)
+	return append(b, res...)
 }
+
+func (h *blake3XOF) Size() int { return h.size }
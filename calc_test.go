@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/dchest/siphash"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,8 +59,8 @@ var (
 			fox:   "b32b",
 			blank: "0000"},
 		"crc24-openpgp": {
-			fox:   "3e7e22",
-			blank: "000000"},
+			fox:   "a2618c",
+			blank: "b704ce"},
 		"crc32-ieee": {
 			fox:   "414fa339",
 			blank: "00000000"},
@@ -96,6 +100,33 @@ var (
 		"streebog-512": {
 			fox:   "d2b793a0bb6cb5904828b5b6dcfb443bb8f33efc06ad09368878ae4cdc8245b97e60802469bed1e7c21a64ff0b179a6a1e0bb74d92965450a0adab69162c00fe",
 			blank: "8e945da209aa869f0455928529bcae4679e9873ab707b55315f56ceb98bef0a7362f715528356ee83cda5f2aac4c6ad2ba3a715c1bcd81cb8e9f90bf4c1c1a8a"},
+		"lsh-256-224": {
+			fox:   "6375aab1e1a1a446aa8d55a3c3f03e85edb96ab886649a34d1f1e876",
+			blank: "48a0d55b2b3d91f26e06f7110fe9ce8ea0e2656bbe344cb1c5930653"},
+		"lsh-256-256": {
+			fox:   "f8025b61eb10d80a7f03ccfb906222a0645bb175fdeee9595f223936edbf7070",
+			blank: "f3cd416a03818217726cb47f4e4d2881c9c29fd445c18b66fb19dea1a81007c1"},
+		"lsh-512-224": {
+			fox:   "3b7d9fc1a1356755abefb5c4c24543068f0cd8d71b57129e8fb53dda",
+			blank: "3c124edfe149b45c067965dae681322cdf52aa2c9d738b8f271b9318"},
+		"lsh-512-256": {
+			fox:   "5e4ebe2017e84f35420bda7486ebbd791e0ece579cc18e49341b9a526466e633",
+			blank: "706df4ebf100f06d5cc9f6c79be5297c3f6f515801dd10fbc1b665a2d7bdb653"},
+		"lsh-512-384": {
+			fox:   "f7c6f97cd902658ab17ba5696aa7bf79d49d36b46aeb9a8a563917a37459c8e28fe299cc8821d76fe6b94dfd5cfc8bc2",
+			blank: "dbb259cf22459368ab2c52b3e1c977288b38670adcb91cae6b8b6a2d646e76f8bd53e5cab0e47c856f55249b895c1730"},
+		"lsh-512-512": {
+			fox:   "bc0a2b9a0c99bdf2c8a83418c4bef13791c97cef25bd2be8fadbbb0f0807c44163085bde435cf7d41db0104dc87eb5cd47cf21698683375647bff65e2ef51e51",
+			blank: "118a2ff2a99e3b2134125e2baf20ebe3bdd034d5a69b29c22fc4995063340b46697801d7f7fb0070568f78e8ed514215fc70af27d6f27b01aa8a1da72b14ce7c"},
+		"kupyna-256": {
+			fox:   "996899f2d7422ceaf552475036b2dc120607eff538abf2b8dff471a98a4740c6",
+			blank: "cd5101d1ccdf0d1d1f4ada56e888cd724ca1a0838a3521e7131d4fb78d0f5eb6"},
+		"kupyna-384": {
+			fox:   "0956d8afa9653b5231614decb1cceb8162ae5b8ff2dc3b02417f86dc4df621d0ca5b1ff399d494766c93a6d2513cae3a",
+			blank: "e445d452aecd46c3298343314ef04019bcfa3f04265a9857f91be91fce197096187ceda78c9c1c021c294a0689198538"},
+		"kupyna-512": {
+			fox:   "d1b469f43e0963735b6cd08a6e75fc370956d8afa9653b5231614decb1cceb8162ae5b8ff2dc3b02417f86dc4df621d0ca5b1ff399d494766c93a6d2513cae3a",
+			blank: "656b2f4cd71462388b64a37043ea55dbe445d452aecd46c3298343314ef04019bcfa3f04265a9857f91be91fce197096187ceda78c9c1c021c294a0689198538"},
 		"md2": {
 			fox:   "03d85a0d629d2c442e987525319fc471",
 			blank: "8350e5a3e24c153df2275c9f80692773"},
@@ -111,6 +142,12 @@ var (
 		"sha1": {
 			fox:   "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12",
 			blank: "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		"sm3": {
+			fox:   "5fdfe814b8573ca021983970fc79b2218c9570369b4859684e2e4c3fc76cb8ea",
+			blank: "1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b"},
+		"has160": {
+			fox:   "abe2b8c711f9e8579aa8eb40757a27b4ef14a7ea",
+			blank: "307964ef34151d37c8047adec7ab50f4ff89762d"},
 		"sha224": {
 			fox:   "730e109bd7a8a32b1cb9d9a09aa2325d2430587ddbc0c38bad911525",
 			blank: "d14a028c2a3a2bc9476102bb288234c415a2b01f828ea62ac5b3e42f"},
@@ -141,29 +178,185 @@ var (
 		"sha3-512": {
 			fox:   "01dedd5de4ef14642445ba5f5b97c15e47b9ad931326e4b0727cd94cefc44fff23f07bf543139939b49128caf436dc1bdee54fcb24023a08d9403f9b4bf0d450",
 			blank: "a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26"},
+		"keccak224": {
+			fox:   "310aee6b30c47350576ac2873fa89fd190cdc488442f3ef654cf23fe",
+			blank: "f71837502ba8e10837bdd8d365adb85591895602fc552b48b7390abd"},
+		"keccak256": {
+			fox:   "4d741b6f1eb29cb2a9b9911c82f56fa8d73b04959d3d9d222895df6c0b28aa15",
+			blank: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		"keccak384": {
+			fox:   "283990fa9d5fb731d786c5bbee94ea4db4910f18c62c03d173fc0a5e494422e8a0b3da7574dae7fa0baf005e504063b3",
+			blank: "2c23146a63a29acf99e73b88f8c24eaa7dc60aa771780ccc006afbfa8fe2479b2dd2b21362337441ac12b515911957ff"},
+		"keccak512": {
+			fox:   "d135bb84d0439dbac432247ee573a23ea7d3c9deb2a968eb31d47c4fb45f1ef4422d6c531b5b9bd6f449ebcc449ea94d0a8f05f62130fda612da53c79659f609",
+			blank: "0eab42de4c3ceb9235fc91acffe746b29c29a8c366b7c60e4e67c466f36a4304c00fa9caf9d87976ba469bcbe06713b435f091ef2769fb160cdab33d3670680e"},
 		"shake128-256": {
 			fox:   "f4202e3c5852f9182a0430fd8144f0a74b95e7417ecae17db0f8cfeed0e3e66e",
 			blank: "7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26"},
 		"shake256-512": {
 			fox:   "2f671343d9b2e1604dc9dcf0753e5fe15c7c64a0d283cbbf722d411a0e36f6ca1d01d1369a23539cd80f7c054b6e5daf9c962cad5b8ed5bd11998b40d5734442",
 			blank: "46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be"},
+		"turboshake128-256": {
+			fox:   "76a1720a4848ab64e67e563f16b8c5aa492b698a4d93429735fd02354657fbf7",
+			blank: "1e415f1c5983aff2169217277d17bb538cd945a397ddec541f1ce41af2c1b74c"},
+		"turboshake256-512": {
+			fox:   "b6e91a412c262c7936b069f67bd21c2f8ecc48bda8dc6eebfbaf6fcaa82191c3974462707ab2a5c5d704b0e874860a2a3fddb588f507c9b4f0417e2b66316090",
+			blank: "367a329dafea871c7802ec67f905ae13c57695dc2c6663c61035f59a18f8e7db11edc0e12e91ea60eb6b32df06dd7f002fbafabb6e13ec1cc20d995547600db0"},
+		"k12": {
+			fox:   "b4f249b4f77c58df170aa4d1723db1127d82f1d98d25ddda561ada459cd11a48",
+			blank: "1ac2d450fc3b4205d19da7bfca1b37513c0803577ac7167f06fe2ce1f0ef39e5"},
+		"cshake128": {
+			fox:   "f4202e3c5852f9182a0430fd8144f0a74b95e7417ecae17db0f8cfeed0e3e66e",
+			blank: "7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26"},
+		"cshake256": {
+			fox:   "2f671343d9b2e1604dc9dcf0753e5fe15c7c64a0d283cbbf722d411a0e36f6ca1d01d1369a23539cd80f7c054b6e5daf9c962cad5b8ed5bd11998b40d5734442",
+			blank: "46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be"},
+		"kmac128": {
+			fox:   "d26ce5ceb3a1bccd03e454835b4b611aa0d5eba4c9940d05fae3deee7a7206b7",
+			blank: "5c135c615152fb4d9784dd1155f9b6034e013fd77165c327dfa4d36701983ef7"},
+		"kmac256": {
+			fox:   "c212695c45a612147c87e14d70890dd154af5704bbbe6e6f78aa6c08a1560eeccb1c9450a7ab0398625c7bd699557bc5c487a66d92a422626041aaa4a77a34e0",
+			blank: "2b70c18a81bb6446868dbc411e0dc1331c4399101d6b8b14ea16e951eee001033207bfe3bede15b946bfc209c62fc5d95e3e7b530b507319f24947d6ad7c18fe"},
+		"parallelhash128": {
+			fox:   "4c1933c6b94b0bb2e67d86f8e5aa840d3426045af1fda9d129e087bcc0003676",
+			blank: "c7b32e3b071f7fb9c58054c93c2f35e0d8051a270d6c0136ef849232c96cd1c5"},
+		"parallelhash256": {
+			fox:   "a83cb1bd5c03b056a75d72a2ae6f953fec13ccff8f764776288331b06d4a0c928a213d146d40e15ec2cdb5e3479095c0305b01576d290e6e6f5fbda32149a45f",
+			blank: "fe94d54ec0a5083a8880b4b4102ba049708ed8d2fd83f489fa5490ba9bf994ab35d8daa2340bbdb9b7b010851df783c7954af215f8ebc5fe3a206602077cb384"},
+		"siphash-1-3": {
+			fox:   "1e450cd0d376f68d",
+			blank: "2c530c1562a7fbd1"},
+		"siphash-1-3-128": {
+			fox:   "a7dbea966fc73d7d1b1342a72ae6bc95",
+			blank: "0db4d9615d9334210333fb31d05e1cb9"},
 		"siphash-2-4": {
 			fox:   "0de4702506520059",
 			blank: "d70077739d4b921e"},
+		"siphash-2-4-128": {
+			fox:   "df8c5ce876c57f25c03f1bb5df591ab2",
+			blank: "5049d74780a3e07d4202ab47d4cef2f4"},
 		"skein512-256": {
 			fox:   "b3250457e05d3060b1a4bbc1428bc75a3f525ca389aeab96cfa34638d96e492a",
 			blank: "39ccc4554a8b31853b9de7a1fe638a24cce6b35a55f2431009e18780335d2621"},
 		"skein512-512": {
 			fox:   "94c2ae036dba8783d0b3f7d6cc111ff810702f5c77707999be7e1c9486ff238a7044de734293147359b4ac7e1d09cd247c351d69826b78dcddd951f0ef912713",
 			blank: "bc5b4c50925519c290cc634277ae3d6257212395cba733bbad37a4af0fa06af41fca7903d06564fea7a2d3730dbdb80c1f85562dfcc070334ea4d1d9e72cba7a"},
+		"apr1": {
+			fox:   "8485e009771dc467ca0a2e826e7e5e00",
+			blank: "1d8ef5fd06f3e034d74b77d055916f67"},
+		"md5crypt": {
+			fox:   "f9cf385c7e9f5232527dd598eb3460c5",
+			blank: "5914e3d59db4b74ca52c747e768952a6"},
+		"sha256crypt": {
+			fox:   "627c4126f7f605bc037f8827f519b6055b5539289e2355f19f094b6c051087ec",
+			blank: "706014101a5125b233e44a9c68de0ba398a3bd9705bd38572b1d849c0d66a20e"},
+		"sha512crypt": {
+			fox:   "62abcf95279a05934589eb97390c3c64ec3fdd8a30807d9263a506fc4a33023b90e11875694eaed01e8e0c8d691d7283ec18b16bb8f8f169fd5823260465019b",
+			blank: "ba8d0a85628a0015a55dbcdada1105fcaf5f4a0d63da1306c7ad947e400ada6b07d3c9dfb5b285a8f84501a911084128a3672631dd4b7a31fc62d391d1470ee9"},
+		"argon2i": {
+			fox:   "2e5f2039747c49512c97f5b3511ec41e806b3d4dbee3f4cf847c02eaf4621ac4",
+			blank: "31d86c6d07da9f096fe72f609a39895c11e69a6ad8c9ed5a5e7e413026f5bd60"},
+		"argon2id": {
+			fox:   "e909dbe2d887cc1fb18bfc3abcdc32bc549d87323e1e06d5d42916665fb73c51",
+			blank: "8819ed2967578a7a500fbe6fd4018538b8960fcd9f700cb82a94d9ef838bd21f"},
+		"bcrypt": {
+			fox:   "d3868e28dbd40d97939def179e3403ab997e896f30c7c0",
+			blank: "1fba351064bb1b6deab786eedd658e82c501f6d857777f"},
+		"pbkdf2-sha1": {
+			fox:   "62d2010a6cfcfe3767fcd62ed29d64eb1cebbbf6",
+			blank: "7f9894580f4165c8974bb506acb0d86126cfb252"},
+		"pbkdf2-sha256": {
+			fox:   "7e7127c7fc8b3fa3d8b5f2a4823d651a3898a3dca469f7c855c10995719847db",
+			blank: "0d60c695f7e95db4f9557f5b2410e77b296801a818bd99ffb8d3dd4972b28c50"},
+		"pbkdf2-sha512": {
+			fox:   "96de1289832663ff97efc7bd9a994661296003c636147d1b8619808c3aab650f7f002d2b366f0dbf039d37d3c24a2f14608503f3885678d232ab642af87855e3",
+			blank: "49b4a7213dfcee4eaddec1e21ea6fc8b9a2c465ae7cd504f411eee6b21d2e5ef76348ae1a9eaa81b55556d13a2434d09022cc407d9136246ef352805f4b4abb5"},
+		"scrypt": {
+			fox:   "c6afcb0d03ed20937fb22786398bdde4d5f31337682677742c9e94284ed0a7a7",
+			blank: "d72c87d0f077c7766f2985dfab30e8955c373a13a1e93d315203939f542ff86e"},
+		"lm": {
+			fox:   "a7b07f9948d8cc7f97c4b0b30cae500f",
+			blank: "aad3b435b51404eeaad3b435b51404ee"},
+		"mscache": {
+			fox:   "6b1234214be58fd7e449f84e838700b0",
+			blank: "be6bc64c94bbc062bcebfb40b4f93304"},
+		"mysql323": {
+			fox:   "7ea6156f183bc34f",
+			blank: "5030573512345671"},
+		"mysql41": {
+			fox:   "a4e4d26fd0c6455e23e2187c3aabe844332aa1b3",
+			blank: "be1bdec0aa74b4dcb079943e70528096cca985f8"},
+		"ntlm": {
+			fox:   "4e6a076ae1b04a815fa6332f69e2e231",
+			blank: "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		"oracle-des": {
+			fox:   "97cd755dd4095ac9",
+			blank: "23083a3ca70dd027"},
+		"postgres-md5": {
+			fox:   "9e107d9d372bb6826bd81d3542a419d6",
+			blank: "d41d8cd98f00b204e9800998ecf8427e"},
 		"tiger192": {
 			fox:   "6d12a41e72e644f017b6f0e2f7b44c6285f06dd5d2c5b075",
 			blank: "3293ac630c13f0245f92bbb1766e16167a4e58492dde73f3"},
+		"ed2k": {
+			fox:   "1bee69a46ba811185c194762abaeae90",
+			blank: "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		"aich": {
+			fox:   "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12",
+			blank: "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		"tth": {
+			fox:   "b2d9a622772cc50b91d876d9c22fa07925beedb05a3de747",
+			blank: "5d9ed00a030e638bdb753a6a24fb900e5a63b8e73e6c25b6"},
 		"whirlpool": {
 			fox:   "b97de512e91e3828b40d2b0fdce9ceb3c4a71f9bea8d88e75c4fa854df36725fd2b52eb6544edcacd6f8beddfea403cb55ae31f03ad62a5ef54e42ee82c3fb35",
 			blank: "19fa61d75522a4669b44e39c1d2e1726c530232130d407f89afee0964997f7a73e83be698b288febcf88e3e03c4f0757ea8964e59b63d93708b138cc42a66eb3"},
 		"xxh64": {fox: "0b242d361fda71bc",
 			blank: "ef46db3751d8e999"},
+		"xxh32": {
+			fox:   "e85ea4de",
+			blank: "02cc5d05"},
+		"xxh3-64": {
+			fox:   "ce7d19a5418fb365",
+			blank: "2d06800538d394c2"},
+		"xxh3-128": {
+			fox:   "ddd650205ca3e7fa24a1cc2e3a8a7651",
+			blank: "99aa06d3014798d86001c324468d497f"},
+		"murmur3-32": {
+			fox:   "2e4ff723",
+			blank: "00000000"},
+		"murmur3-128": {
+			fox:   "e34bbc7bbc071b6c7a433ca9c49a9347",
+			blank: "00000000000000000000000000000000"},
+		"city32": {
+			fox:   "a339c810",
+			blank: "dc56d17a"},
+		"city64": {
+			fox:   "c268724928feca7d",
+			blank: "9ae16a3b2f90404f"},
+		"city128": {
+			fox:   "bf1498f876dbe279a7f9a86a2d60c968",
+			blank: "3cb540c392e51e293df09dfc64c09a2b"},
+		"farm32": {
+			fox:   "ec998320",
+			blank: "dc56d17a"},
+		"farm64": {
+			fox:   "abbe83f33b1b5134",
+			blank: "9ae16a3b2f90404f"},
+		"farm128": {
+			fox:   "bf1498f876dbe279a7f9a86a2d60c968",
+			blank: "3cb540c392e51e293df09dfc64c09a2b"},
+		"highwayhash-64": {
+			fox:   "3e33354e676e2d55",
+			blank: "7035da75b9d54469"},
+		"highwayhash-128": {
+			fox:   "d59d55e677071404dcded33a97cfee4b",
+			blank: "2f6380a491d0415ae514c834a55b3b00"},
+		"highwayhash-256": {
+			fox:   "40e0a9717f9dee85a7c86aadee4e2bd884656a3eec42a8172d340faa3cb127de",
+			blank: "5415de88e9eeaa62f54662c43dc403544bf5d4b1ec544ea07195b25d437acd85"},
+		"wyhash": {
+			fox:   "cf850ba9ddd12bb3",
+			blank: "0000000000000000"},
 	}
 )
 
@@ -182,8 +375,219 @@ func TestCalcExpectedHashes(t *testing.T) {
 	}
 }
 
+func TestSumMany(t *testing.T) {
+	ids := []string{}
+	for algo := range expectedHashes {
+		ids = append(ids, algo)
+	}
+	ids = append(ids, "tiger")
+
+	calc := NewCalculator(strings.NewReader(fox))
+	res, err := calc.SumMany(ids)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(ids), len(res))
+	for _, algo := range ids {
+		expected, ok := expectedHashes[algo]
+		if !ok {
+			expected = expectedHashes[resolveAlgoAliases(algo)]
+		}
+		assert.Equal(t, expected[fox], hex.EncodeToString(res[algo]), algo)
+	}
+
+	_, err = NewCalculator(strings.NewReader(fox)).SumMany([]string{"md5", "nope"})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDigest(t *testing.T) {
+	digest, err := NewCalculator(strings.NewReader(fox)).Digest("sha256")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha256:"+expectedHashes["sha256"][fox], digest)
+
+	for _, tc := range []struct {
+		digest   string
+		expected bool
+	}{
+		{"sha256:" + expectedHashes["sha256"][fox], true},
+		{"sha512:" + expectedHashes["sha512"][fox], true},
+		{"sha256:" + expectedHashes["sha256"][blank], false},
+	} {
+		ok, err := NewCalculator(strings.NewReader(fox)).VerifyDigest(tc.digest)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.expected, ok, tc.digest)
+	}
+
+	_, err = NewCalculator(strings.NewReader(fox)).VerifyDigest(expectedHashes["sha256"][fox])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestKeyedHash(t *testing.T) {
+	// test vector from the SipHash paper, appendix A
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	msg, _ := hex.DecodeString("000102030405060708090a0b0c0d0e")
+
+	calc := NewCalculator(bytes.NewReader(msg))
+	calc.Key(key)
+	res, err := calc.Sum("siphash-2-4")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "e545be4961ca29a1", hex.EncodeToString(res))
+
+	// vectors from the SipHash reference implementation (vectors.h), the -1-3 variants
+	// built with cROUNDS=1 and dROUNDS=3
+	msg64 := make([]byte, 64)
+	for i := range msg64 {
+		msg64[i] = byte(i)
+	}
+	for _, tc := range []struct {
+		algo     string
+		msg      []byte
+		expected string
+	}{
+		{"siphash-2-4-128", msg, "5493e99933b0a8117e08ec0f97cfc3d9"},
+		{"siphash-2-4", msg64, "d8ca02850bc4d2ac"},
+		{"siphash-2-4-128", msg64, "1eaf077dc0d4cd3f8cad4d383658a74b"},
+		{"siphash-1-3", msg, "5699512a6dd820d3"},
+		{"siphash-1-3-128", msg, "c17e5505b2bd526c2921cdec1e7e0109"},
+		{"siphash-1-3", msg64, "65604a4bec9779f1"},
+		{"siphash-1-3-128", msg64, "1253def24b4aa5364ec8a759ba6a66c2"},
+	} {
+		h, err := NewKeyedHash(tc.algo, key)
+		assert.Equal(t, nil, err)
+		_, _ = h.Write(tc.msg)
+		assert.Equal(t, tc.expected, hex.EncodeToString(h.Sum(nil)), tc.algo)
+	}
+
+	// siphash-2-4 must agree with the reference implementation in dchest/siphash
+	for algo, expected := range map[string]hash.Hash{
+		"siphash-2-4":     siphash.New(key),
+		"siphash-2-4-128": siphash.New128(key),
+	} {
+		h, _ := NewKeyedHash(algo, key)
+		for i := 0; i < len(fox); i++ {
+			_, _ = expected.Write([]byte{fox[i]})
+			_, _ = h.Write([]byte{fox[i]})
+			assert.Equal(t, expected.Sum(nil), h.Sum(nil), algo)
+		}
+	}
+
+	_, err = NewKeyedHash("siphash-1-3", []byte("short"))
+	assert.NotEqual(t, nil, err)
+
+	_, err = NewKeyedHash("sha1", key)
+	assert.NotEqual(t, nil, err)
+
+	calc = NewCalculator(bytes.NewReader(msg))
+	calc.Key(key)
+	calc.HMACKey(key)
+	_, err = calc.Sum("siphash-2-4")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestHashParams(t *testing.T) {
+	// blake3 test vectors use input bytes 0, 1, 2, .., 250, 0, 1, ..
+	blake3Input := make([]byte, 1025)
+	for i := range blake3Input {
+		blake3Input[i] = byte(i % 251)
+	}
+	blake3Key := hex.EncodeToString([]byte("whats the Elvish word for friend"))
+
+	tests := []struct {
+		algo     string
+		input    []byte
+		expected string
+	}{
+		// BLAKE2 values from python hashlib
+		{"blake2b:output=20", []byte(fox), "3c523ed102ab45a37d54f5610d5a983162fde84f"},
+		{"blake2b:output=32,key=736563726574206b6579,salt=00112233445566778899aabbccddeeff,person=676f68617368207465737473",
+			[]byte(fox), "89195d484af1eaff080fc67fc51be6f034fbb8df291a749da9f3de4fd5a76052"},
+		{"blake2s:output=16,key=736563726574206b6579,salt=0011223344556677,person=676f68617368",
+			[]byte(fox), "4d1dab9c4938870e0907f401a79774cd"},
+		{"blake2b-512:output=20", []byte(fox), "3c523ed102ab45a37d54f5610d5a983162fde84f"},
+
+		// BLAKE3 values from the official test vectors
+		{"blake3:output=40", blake3Input,
+			"d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444f4c4a22b4b399155"},
+		{"blake3:key=" + blake3Key, blake3Input,
+			"357dc55de0c7e382c900fd6e320acc04146be01db6a8ce7210b7189bd664ea69"},
+		{"blake3:context=BLAKE3 2019-12-27 16:29:52 test vectors context", blake3Input,
+			"effaa245f065fbf82ac186839a249707c3bddf6d3fdda22d1b95a3c970379bcb"},
+		{"blake3:context=BLAKE3 2019-12-27 16:29:52 test vectors context", []byte{},
+			"2cc39783c223154fea8dfb7c1b1660f2ac2dcbd1c1de8277b0b0dd39b7e50d7d"},
+
+		{"siphash-2-4:key=000102030405060708090a0b0c0d0e0f", []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+			"e545be4961ca29a1"},
+	}
+	for _, test := range tests {
+		calc := NewCalculator(bytes.NewReader(test.input))
+		res, err := calc.Sum(test.algo)
+		assert.Equal(t, nil, err, test.algo)
+		assert.Equal(t, test.expected, hex.EncodeToString(res), test.algo)
+	}
+
+	invalid := []string{
+		"sha1:output=10",
+		"blake2b:output",
+		"blake2b:output=ten",
+		"blake2b:output=65",
+		"blake2b:key=nothex",
+		"blake2b:bogus=1",
+		"blake3:key=00",
+		"blake3:key=" + blake3Key + ",context=x",
+		"blake3:output=0",
+		"blake3:output=1048577",
+		"blake3:output=1000000000000",
+	}
+	for _, algo := range invalid {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	// RFC 4231 and RFC 2202 test case 2
+	key := []byte("Jefe")
+	msg := "what do ya want for nothing?"
+	expected := map[string]string{
+		"md5":    "750c783e6ab0b503eaa86e310a5db738",
+		"sha1":   "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79",
+		"sha256": "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		"sha512": "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+	}
+	for algo, hash := range expected {
+		calc := NewCalculator(strings.NewReader(msg))
+		calc.HMACKey(key)
+		res, err := calc.Sum(algo)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, hash, hex.EncodeToString(res), algo)
+	}
+
+	calc := NewCalculator(strings.NewReader(msg))
+	calc.HMACKey(key)
+	res, err := calc.SumMany([]string{"sha1", "sha256"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expected["sha1"], hex.EncodeToString(res["sha1"]))
+	assert.Equal(t, expected["sha256"], hex.EncodeToString(res["sha256"]))
+
+	// every algorithm with a block size supports HMAC
+	for _, algo := range registeredHashes() {
+		h, err := NewHMAC(algo.Name, key)
+		if algo.New().BlockSize() > 1 {
+			assert.Equal(t, nil, err, algo.Name)
+			assert.Equal(t, algo.Bits, len(h.Sum(nil))*8, algo.Name)
+		} else {
+			assert.NotEqual(t, nil, err, algo.Name)
+		}
+	}
+
+	_, err = NewHMAC("crc32", key)
+	assert.NotEqual(t, nil, err)
+}
+
 func TestFuzzHashes(t *testing.T) {
 	for algo := range expectedHashes {
+		// password hashes are slow by design
+		if info, _ := LookupHash(algo); info.Family == "password" {
+			continue
+		}
 		for i := 0; i < iterationsPerAlgo; i++ {
 			var rnd []byte
 			f.Fuzz(&rnd)
@@ -194,19 +598,82 @@ func TestFuzzHashes(t *testing.T) {
 	}
 }
 
-func TestHashersAndAlgosDefines(t *testing.T) {
-	for algo := range algos {
-		if _, ok := hashers[algo]; !ok {
-			t.Error("algo not defined in hashers map", algo)
+func TestHashersDefines(t *testing.T) {
+	for _, algo := range registeredHashes() {
+		// crc algorithms are tested with their check values in TestCRCModels
+		if _, ok := expectedHashes[algo.Name]; !ok && algo.Family != "crc" {
+			t.Error("algo lacks testcase in expectedHashes map", algo.Name)
 		}
-		if _, ok := expectedHashes[algo]; !ok {
-			t.Error("algo lacks testcase in expectedHashes map", algo)
+		if algo.Family == "" {
+			t.Error("algo lacks family", algo.Name)
 		}
+		h := algo.New()
+		assert.Equal(t, algo.Bits, h.Size()*8, algo.Name+" Size()")
+		assert.Equal(t, algo.Bits, len(h.Sum(nil))*8, algo.Name+" Sum()")
+	}
+}
+
+func TestNewHash(t *testing.T) {
+	for algo, forms := range expectedHashes {
+		h, err := NewHash(algo)
+		assert.Equal(t, nil, err)
+
+		// write in chunks, and verify Sum does not change the state
+		_, _ = h.Write([]byte(fox[:10]))
+		_ = h.Sum(nil)
+		_, _ = h.Write([]byte(fox[10:]))
+		assert.Equal(t, forms[fox], hex.EncodeToString(h.Sum(nil)), algo)
+
+		h.Reset()
+		assert.Equal(t, forms[blank], hex.EncodeToString(h.Sum(nil)), algo+" after Reset")
+	}
+
+	_, err := NewHash("nope")
+	assert.NotEqual(t, nil, err)
+}
+
+// patternReader reads `n` bytes of a repeating pattern without holding them in memory
+type patternReader struct {
+	n int
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	for i := range p {
+		p[i] = byte(r.n - i)
 	}
-	for algo := range hashers {
-		if _, ok := algos[algo]; !ok {
-			t.Error("algo not defined in algos map", algo)
+	r.n -= len(p)
+	return len(p), nil
+}
+
+func TestHashersStreamingMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	const size = 4 << 20
+	for _, algo := range registeredHashes() {
+		// password hashes are calculated over the complete password, and CityHash and
+		// FarmHash read the end of the input first, up to bufferedHashLimit
+		if isStringInSlice(algo.Family, []string{"password", "legacy", "city", "farm"}) {
+			continue
 		}
+		h := algo.New()
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		_, err := io.Copy(h, &patternReader{n: size})
+		assert.Equal(t, nil, err, algo.Name)
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+
+		held := int64(after.HeapAlloc) - int64(before.HeapAlloc)
+		assert.True(t, held < size/8, fmt.Sprintf("%s holds %d bytes after hashing %d bytes", algo.Name, held, size))
+		runtime.KeepAlive(h)
 	}
 }
 
@@ -232,6 +699,45 @@ func ExampleNewCalculator() {
 	// Output: 2aae6c35c94fcfb415dbe95f408b9ce91ee846ed
 }
 
+func BenchmarkFastHashes(b *testing.B) {
+	data := bytes.Repeat([]byte(fox), 100000)
+	for _, algo := range []string{
+		"adler32", "crc32", "fnv1a-64", "xxh32", "xxh64", "xxh3-64", "xxh3-128", "murmur3-32", "murmur3-128",
+		"city64", "city128", "farm64", "highwayhash-64", "highwayhash-256", "siphash-2-4", "wyhash",
+	} {
+		b.Run(algo, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				calc := NewCalculator(bytes.NewReader(data))
+				_, _ = calc.Sum(algo)
+			}
+		})
+	}
+}
+
+func BenchmarkKeccakHashes(b *testing.B) {
+	data := bytes.Repeat([]byte(fox), 100000)
+	for _, algo := range []string{"sha3-256", "keccak256", "shake128", "turboshake128", "k12"} {
+		b.Run(algo, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				calc := NewCalculator(bytes.NewReader(data))
+				_, _ = calc.Sum(algo)
+			}
+		})
+	}
+}
+
+func BenchmarkSumMany(b *testing.B) {
+	data := bytes.Repeat([]byte(fox), 100000)
+	ids := []string{"md5", "sha1", "sha256", "sha512"}
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		calc := NewCalculator(bytes.NewReader(data))
+		_, _ = calc.SumMany(ids)
+	}
+}
+
 func BenchmarkCrc8ATM(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		r := strings.NewReader("dsfgklhkjsdhfgkjhsdkjfghljksdhfgjkhsdfgkjhjksdfhgkhsdfgksdfg")
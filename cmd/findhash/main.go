@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/alecthomas/kingpin"
@@ -12,7 +16,7 @@ import (
 )
 
 var (
-	hash        = kingpin.Arg("hash", "Hash to crack, in hex string").Required().String()
+	hash        = kingpin.Arg("hash", "Hash to crack, in hex string or as a crypt(3) string").String()
 	algo        = kingpin.Flag("algo", "Hash algorithm to use.").String()
 	allowedKeys = kingpin.Flag("allowed", "Allowed keys to use.").String()
 	minLength   = kingpin.Flag("min-length", "Minimum length.").Int()
@@ -21,6 +25,11 @@ var (
 	random      = kingpin.Flag("random", "Random mutation mode.").Bool()
 	reverse     = kingpin.Flag("reverse", "Reverse order (if not random mode).").Bool()
 	dictionary  = kingpin.Flag("dictionary", "Dictionary file.").String()
+	crcSamples  = kingpin.Flag("crc-samples", "File with a message and its CRC per line, in hex, to find the CRC model.").String()
+	crcWidth    = kingpin.Flag("crc-width", "CRC width in bits (with --crc-samples).").Int()
+	forge       = kingpin.Flag("forge", "File to patch for its CRC to become hash (with --algo).").String()
+	forgeOffset = kingpin.Flag("forge-offset", "Offset of bytes to patch (with --forge), default is to append.").Default("-1").Int()
+	forgeOutput = kingpin.Flag("forge-output", "Write the patched file (with --forge), default is to show the bytes.").String()
 	startTime   = time.Now()
 	result      = ""
 )
@@ -47,11 +56,34 @@ func main() {
 		}
 	}()
 
-	if *dictionary != "" {
-		if *algo != "" {
-			fmt.Println("ERROR dictionary and algo dont mix")
+	if *crcSamples != "" {
+		if *hash != "" {
+			fmt.Println("ERROR crc-samples and hash dont mix")
 			os.Exit(1)
 		}
+		runCRCFinder()
+		return
+	}
+
+	if *hash == "" {
+		fmt.Println("ERROR hash must be set")
+		os.Exit(1)
+	}
+
+	if *forge != "" {
+		if *algo == "" {
+			fmt.Println("ERROR algo must be set")
+			os.Exit(1)
+		}
+		if *dictionary != "" {
+			fmt.Println("ERROR forge and dictionary dont mix")
+			os.Exit(1)
+		}
+		runForge()
+		return
+	}
+
+	if *dictionary != "" {
 		if *minLength != 0 {
 			fmt.Println("ERROR dictionary and minLength dont mix")
 			os.Exit(1)
@@ -65,7 +97,8 @@ func main() {
 
 	} else {
 
-		if *algo == "" {
+		// crypt strings such as "$6$salt$hash" give the algorithm
+		if *algo == "" && !strings.HasPrefix(*hash, "$") {
 			fmt.Println("ERROR algo must be set")
 			os.Exit(1)
 		}
@@ -90,6 +123,9 @@ func runDictionary() {
 		return
 	}
 
+	if *algo != "" {
+		dict.Algo(*algo)
+	}
 	dict.Prefix(*prefix)
 	dict.Suffix(*suffix)
 	dict.ExpectedHash(*hash)
@@ -134,3 +170,98 @@ func runHasher() {
 
 	fmt.Println("result: ", result)
 }
+
+func runForge() {
+
+	data, err := ioutil.ReadFile(*forge)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+	target, err := hex.DecodeString(*hash)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+
+	offset := *forgeOffset
+	if offset == -1 {
+		offset = len(data)
+	}
+	patch, err := gohash.ForgeCRC(*algo, data, offset, target)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+
+	if *forgeOutput == "" {
+		fmt.Printf("offset: %d, bytes: %x\n", offset, patch)
+		return
+	}
+	res := append([]byte{}, data[:offset]...)
+	res = append(res, patch...)
+	if offset < len(data) {
+		res = append(res, data[offset+len(patch):]...)
+	}
+	if err := ioutil.WriteFile(*forgeOutput, res, 0644); err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+}
+
+func runCRCFinder() {
+
+	finder := gohash.NewCRCFinder()
+	finder.Width(*crcWidth)
+
+	f, err := os.Open(*crcSamples)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			fmt.Printf("ERROR line %d: expected message and crc\n", line)
+			os.Exit(1)
+		}
+		message, err := hex.DecodeString(fields[0])
+		if err != nil {
+			fmt.Printf("ERROR line %d: %v\n", line, err)
+			os.Exit(1)
+		}
+		crc, err := hex.DecodeString(fields[1])
+		if err != nil {
+			fmt.Printf("ERROR line %d: %v\n", line, err)
+			os.Exit(1)
+		}
+		if err := finder.AddSample(message, crc); err != nil {
+			fmt.Printf("ERROR line %d: %v\n", line, err)
+			os.Exit(1)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+
+	models, err := finder.Find()
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+	if len(models) == 0 {
+		fmt.Println("no match")
+		return
+	}
+	for _, model := range models {
+		fmt.Println(model)
+	}
+}
@@ -2,19 +2,25 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/alecthomas/kingpin"
 	"github.com/fatih/color"
 	"github.com/martinlindhe/gohash"
+	isatty "github.com/mattn/go-isatty"
 )
 
 var (
 	fileName      = kingpin.Flag("file", "Input file to read (optional).").Short('i').String()
-	algo          = kingpin.Arg("algo", "Hash algorithm to use.").String()
+	algo          = kingpin.Arg("algo", "Hash algorithm to use, or comma separated list of algorithms.").String()
 	listAlgos     = kingpin.Flag("list-algos", "List available hash algorithms.").Short('A').Bool()
 	encoding      = kingpin.Flag("encoding", "Output encoding.").Short('e').Default("hex").String()
 	listEncodings = kingpin.Flag("list-encodings", "List available encodings.").Short('E').Bool()
@@ -24,6 +30,19 @@ var (
 	reverseBytes  = kingpin.Flag("reverse-bytes", "Reverse byte order of displayed hex value.").Bool()
 	debugAllocs   = kingpin.Flag("debug-allocs", "Debugging: print memory allocations at end of execution.").Bool()
 	bsdSyntax     = kingpin.Flag("bsd", "Output result in BSD syntax.").Bool()
+	key           = kingpin.Flag("key", "Key for keyed algorithms, such as siphash, highwayhash, blake2 and blake3.").String()
+	keyFile       = kingpin.Flag("key-file", "Key for keyed algorithms, read from file.").String()
+	hmacKey       = kingpin.Flag("hmac-key", "Calculate HMAC using key.").String()
+	hmacKeyFile   = kingpin.Flag("hmac-key-file", "Calculate HMAC using key read from file.").String()
+	keyEncoding   = kingpin.Flag("key-encoding", "Encoding of key, eg hex or base64 (default raw).").String()
+	saveState     = kingpin.Flag("save-state", "Save the hash state to file, to continue hashing appended data later.").String()
+	resumeState   = kingpin.Flag("resume-state", "Continue hashing from a state saved with --save-state.").String()
+	verifyCrypt   = kingpin.Flag("verify-crypt", "Verify the input password against a crypt(3) string, such as $6$salt$hash.").String()
+	magnet        = kingpin.Flag("magnet", "Output a magnet link, with algo ed2k, aich, tth, sha1 or md5 (default ed2k,aich,tth).").Bool()
+	goModule      = kingpin.Flag("go-module", "Module and version of a directory for dirhash, as module@version (default from the module cache path).").String()
+	verifyGoSum   = kingpin.Flag("verify-gosum", "Verify the module cache against a go.sum file.").String()
+	modCache      = kingpin.Flag("modcache", "Module cache directory for --verify-gosum (default GOMODCACHE).").String()
+	tuple         = kingpin.Flag("tuple", "Tuple element for tuplehash128 and tuplehash256, may be repeated.").Strings()
 
 	white  = color.New(color.FgWhite).SprintFunc()
 	yellow = color.New(color.FgYellow).SprintFunc()
@@ -45,24 +64,467 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *verifyCrypt != "" {
+		if *algo != "" {
+			fmt.Println("error: verify-crypt and algo dont mix")
+			os.Exit(1)
+		}
+		runVerifyCrypt()
+		return
+	}
+
+	if *verifyGoSum != "" {
+		if *algo != "" {
+			fmt.Println("error: verify-gosum and algo dont mix")
+			os.Exit(1)
+		}
+		runVerifyGoSum()
+		return
+	}
+
+	if *magnet {
+		if *saveState != "" || *resumeState != "" {
+			fmt.Println("error: magnet and state dont mix")
+			os.Exit(1)
+		}
+		if *algo == "" {
+			*algo = "ed2k,aich,tth"
+		}
+	}
+
 	if *algo == "" {
 		fmt.Println("error: required algorithm not provided, try --help")
 		os.Exit(1)
 	}
 
+	// the git-blob header holds the size, so the input is hashed from a file
+	if *algo == "git-blob" || *algo == "git-blob-sha1" || *algo == "git-blob-sha256" {
+		if *saveState != "" || *resumeState != "" || *magnet {
+			fmt.Println("error: git-blob and state or magnet dont mix")
+			os.Exit(1)
+		}
+		runGitBlob()
+		return
+	}
+
+	// git-tree hashes the directory given with --file
+	if *algo == "git-tree" || *algo == "git-tree-sha256" {
+		if *saveState != "" || *resumeState != "" || *magnet {
+			fmt.Println("error: git-tree and state or magnet dont mix")
+			os.Exit(1)
+		}
+		runGitTree()
+		return
+	}
+
+	// ethereum-address only takes a public key
+	if *algo == "ethereum-address" || *algo == "eth-address" {
+		if *saveState != "" || *resumeState != "" || *magnet {
+			fmt.Println("error: ethereum-address and state or magnet dont mix")
+			os.Exit(1)
+		}
+		runEthereumAddress()
+		return
+	}
+
+	// tuplehash hashes the elements given with --tuple
+	if strings.HasPrefix(*algo, "tuplehash") {
+		if *fileName != "" || *saveState != "" || *resumeState != "" || *magnet {
+			fmt.Println("error: tuplehash and file, state or magnet dont mix")
+			os.Exit(1)
+		}
+		runTupleHash()
+		return
+	}
+	if len(*tuple) > 0 {
+		fmt.Println("error: tuple requires tuplehash128 or tuplehash256")
+		os.Exit(1)
+	}
+
+	// dirhash hashes the module directory, zip or go.mod given with --file
+	if *algo == "dirhash" {
+		if *saveState != "" || *resumeState != "" || *magnet {
+			fmt.Println("error: dirhash and state or magnet dont mix")
+			os.Exit(1)
+		}
+		runDirHash()
+		return
+	}
+
 	r, err := gohash.ReadPipeOrFile(*fileName)
 	if err != nil {
 		fmt.Println("error:", err)
 		os.Exit(1)
 	}
 
+	if r.IsPipe {
+		*fileName = "-"
+	}
+
+	// the magnet link has the input size
+	counter := &countingReader{Reader: r.Reader}
+	r.Reader = counter
+
+	// all algorithms are calculated in a single pass over the input
+	ids := gohash.SplitAlgoList(*algo)
 	calc := gohash.NewCalculator(r.Reader)
-	hash, err := calc.Sum(*algo)
+
+	if *key != "" || *keyFile != "" {
+		key, err := readKey(*key, *keyFile)
+		if err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		calc.Key(key)
+	}
+
+	if *hmacKey != "" || *hmacKeyFile != "" {
+		key, err := readKey(*hmacKey, *hmacKeyFile)
+		if err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		calc.HMACKey(key)
+	}
+
+	var hashes map[string][]byte
+	if *saveState != "" || *resumeState != "" {
+		hashes, err = sumWithState(calc, ids, r)
+	} else {
+		hashes, err = sumWithProgress(calc, ids, r)
+	}
 	if err != nil {
 		fmt.Println("error: ", err)
 		os.Exit(1)
 	}
 
+	if *magnet {
+		name := ""
+		if !r.IsPipe {
+			name = filepath.Base(*fileName)
+		}
+		link, err := gohash.MagnetLink(name, counter.n, ids, hashes)
+		if err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+		fmt.Println(link)
+		return
+	}
+
+	for _, id := range ids {
+		if err := printHash(id, hashes[id]); err != nil {
+			fmt.Println("error", err)
+			os.Exit(1)
+		}
+	}
+
+	if *debugAllocs {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		fmt.Printf("\nAlloc = %v\nTotalAlloc = %v\nSys = %v\nNumGC = %v\n\n", m.Alloc/1024, m.TotalAlloc/1024, m.Sys/1024, m.NumGC)
+	}
+}
+
+const (
+	// files of at least this size show a progress bar
+	progressMinSize = 64 * 1024 * 1024
+
+	progressBarWidth = 40
+)
+
+// hashes the input, showing a progress bar on stderr for large files if it is a terminal
+func sumWithProgress(calc *gohash.Calculator, ids []string, r *gohash.AppInputData) (map[string][]byte, error) {
+	if r.IsPipe || !isatty.IsTerminal(os.Stderr.Fd()) {
+		return calc.SumMany(ids)
+	}
+	fi, err := os.Stat(*fileName)
+	if err != nil || fi.Size() < progressMinSize {
+		return calc.SumMany(ids)
+	}
+	total := fi.Size()
+	opts := &gohash.SumOptions{Progress: func(p gohash.Progress) {
+		done := int(int64(p.Bytes) * progressBarWidth / total)
+		if done > progressBarWidth {
+			done = progressBarWidth
+		}
+		fmt.Fprintf(os.Stderr, "\r[%s%s] %3d%% %7.1f MiB/s",
+			strings.Repeat("=", done), strings.Repeat(" ", progressBarWidth-done),
+			int64(p.Bytes)*100/total, p.BytesPerSecond()/(1024*1024))
+	}}
+	hashes, err := calc.SumManyContext(context.Background(), ids, opts)
+	// clear the progress bar
+	fmt.Fprint(os.Stderr, "\r"+strings.Repeat(" ", progressBarWidth+20)+"\r")
+	return hashes, err
+}
+
+// resumes and saves the hash state. When resuming on a file, the bytes already
+// hashed are skipped, piped input is taken to be the appended data only
+func sumWithState(calc *gohash.Calculator, ids []string, r *gohash.AppInputData) (map[string][]byte, error) {
+	if len(ids) != 1 {
+		return nil, fmt.Errorf("--save-state and --resume-state take a single algorithm")
+	}
+	if *resumeState != "" {
+		state, err := ioutil.ReadFile(*resumeState)
+		if err != nil {
+			return nil, err
+		}
+		length, err := gohash.StateLength(state)
+		if err != nil {
+			return nil, err
+		}
+		if seeker, ok := r.Reader.(io.Seeker); ok && !r.IsPipe {
+			if _, err := seeker.Seek(int64(length), io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		calc.ResumeState(state)
+	}
+	if *saveState == "" {
+		sum, err := calc.Sum(ids[0])
+		return map[string][]byte{ids[0]: sum}, err
+	}
+	sum, state, err := calc.SumState(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(*saveState, state, 0644); err != nil {
+		return nil, err
+	}
+	return map[string][]byte{ids[0]: sum}, nil
+}
+
+// verifies the input against --verify-crypt, exiting with status 1 if it does not match
+func runVerifyCrypt() {
+	r, err := gohash.ReadPipeOrFile(*fileName)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	password, err := ioutil.ReadAll(r.Reader)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	ok, err := gohash.VerifyCrypt(*verifyCrypt, password)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	if !ok {
+		fmt.Println("FAILED")
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
+
+// countingReader counts the bytes read
+type countingReader struct {
+	io.Reader
+	n uint64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += uint64(n)
+	return n, err
+}
+
+// returns the key from the `key` argument or from `keyFile`, decoded according to --key-encoding
+func readKey(key string, keyFile string) ([]byte, error) {
+	if key != "" && keyFile != "" {
+		return nil, fmt.Errorf("key and key file dont mix")
+	}
+	data := []byte(key)
+	if keyFile != "" {
+		var err error
+		data, err = ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if *keyEncoding == "" || *keyEncoding == "raw" {
+		return data, nil
+	}
+	coder := gohash.NewCoder(*keyEncoding)
+	return coder.Decode(bytes.NewReader(data))
+}
+
+func runGitBlob() {
+
+	r, err := gohash.ReadPipeOrFile(*fileName)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	name := *fileName
+	if r.IsPipe {
+		// piped input is written to a temporary file, to know its size
+		*fileName = "-"
+		name, err = writeTempFile(r.Reader)
+		if err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+	}
+	format := "sha1"
+	if *algo == "git-blob-sha256" {
+		format = "sha256"
+	}
+	id, err := gohash.GitBlob(name, format)
+	if r.IsPipe {
+		os.Remove(name)
+	}
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	if err := printHash(*algo, id); err != nil {
+		fmt.Println("error", err)
+		os.Exit(1)
+	}
+}
+
+// writes `r` to a new temporary file, returning its name
+func writeTempFile(r io.Reader) (string, error) {
+	f, err := ioutil.TempFile("", "hasher")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func runGitTree() {
+
+	if *fileName == "" {
+		fmt.Println("error: git-tree requires a directory, given with --file")
+		os.Exit(1)
+	}
+	format := "sha1"
+	if *algo == "git-tree-sha256" {
+		format = "sha256"
+	}
+	id, err := gohash.GitTree(*fileName, format)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	if err := printHash(*algo, id); err != nil {
+		fmt.Println("error", err)
+		os.Exit(1)
+	}
+}
+
+func runEthereumAddress() {
+
+	r, err := gohash.ReadPipeOrFile(*fileName)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	if r.IsPipe {
+		*fileName = "-"
+	}
+	// a public key is at most 65 bytes, more input is rejected as too long
+	pubKey, err := ioutil.ReadAll(io.LimitReader(r.Reader, 66))
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	addr, err := gohash.EthereumAddress(pubKey)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	sum, _ := hex.DecodeString(strings.TrimPrefix(addr, "0x"))
+	if err := printHash(*algo, sum); err != nil {
+		fmt.Println("error", err)
+		os.Exit(1)
+	}
+}
+
+func runTupleHash() {
+
+	elements := [][]byte{}
+	for _, e := range *tuple {
+		elements = append(elements, []byte(e))
+	}
+	sum, err := gohash.SumTuple(*algo, elements)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	// the elements are not read from a file
+	*skipFilename = true
+	if err := printHash(*algo, sum); err != nil {
+		fmt.Println("error", err)
+		os.Exit(1)
+	}
+}
+
+func runDirHash() {
+
+	if *fileName == "" {
+		fmt.Println("error: dirhash requires a directory or zip, given with --file")
+		os.Exit(1)
+	}
+	sum, err := gohash.GoModHash(*fileName, *goModule)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	if *skipFilename {
+		fmt.Println(sum)
+	} else {
+		fmt.Println(sum + "  " + *fileName)
+	}
+}
+
+func runVerifyGoSum() {
+
+	f, err := os.Open(*verifyGoSum)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if *modCache == "" {
+		*modCache = gohash.GoModCache()
+	}
+	results, err := gohash.VerifyGoSum(f, *modCache)
+	if err != nil {
+		fmt.Println("error:", err)
+		os.Exit(1)
+	}
+	failed := 0
+	for _, res := range results {
+		switch {
+		case res.OK():
+			fmt.Println("OK     ", res.Module, res.Version)
+		case res.Actual == "":
+			fmt.Println("MISSING", res.Module, res.Version)
+			failed++
+		default:
+			fmt.Println("FAILED ", res.Module, res.Version, res.Actual)
+			failed++
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printHash(algo string, hash []byte) error {
+
 	if *reverseBytes {
 		rev := []byte{}
 		for i := len(hash) - 1; i >= 0; i-- {
@@ -74,15 +536,11 @@ func main() {
 	coder := gohash.NewCoder(*encoding)
 	encodedHash, err := coder.Encode(bytes.NewReader(hash))
 	if err != nil {
-		fmt.Println("error", err)
-		os.Exit(1)
+		return err
 	}
 
-	if r.IsPipe {
-		*fileName = "-"
-	}
 	if *bsdSyntax {
-		fmt.Print(strings.ToUpper(*algo))
+		fmt.Print(strings.ToUpper(algo))
 		if !*skipFilename {
 			fmt.Print(" (")
 			if !*noColors {
@@ -105,9 +563,6 @@ func main() {
 			fmt.Print(string(encodedHash))
 		}
 		if !*skipFilename {
-			if r.IsPipe {
-				*fileName = "-"
-			}
 			fmt.Print("  ")
 			if !*noColors {
 				fmt.Print(white(*fileName))
@@ -120,10 +575,5 @@ func main() {
 	if !*skipNewline {
 		fmt.Println()
 	}
-
-	if *debugAllocs {
-		var m runtime.MemStats
-		runtime.ReadMemStats(&m)
-		fmt.Printf("\nAlloc = %v\nTotalAlloc = %v\nSys = %v\nNumGC = %v\n\n", m.Alloc/1024, m.TotalAlloc/1024, m.Sys/1024, m.NumGC)
-	}
+	return nil
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/martinlindhe/gohash"
+)
+
+var (
+	path        = kingpin.Arg("path", "File or directory.").Required().String()
+	pieceLength = kingpin.Flag("piece-length", "Piece length in KiB, a power of two of 16 or more (default by size).").Int()
+	files       = kingpin.Flag("files", "List the v2 pieces root of each file.").Bool()
+	verify      = kingpin.Flag("verify", "Verify path against a .torrent file.").String()
+)
+
+func main() {
+
+	// support -h for --help
+	kingpin.CommandLine.HelpFlag.Short('h')
+	kingpin.Parse()
+
+	if *verify != "" {
+		if *pieceLength != 0 {
+			fmt.Println("ERROR verify and piece-length dont mix")
+			os.Exit(1)
+		}
+		if *files {
+			fmt.Println("ERROR verify and files dont mix")
+			os.Exit(1)
+		}
+		runVerify()
+		return
+	}
+
+	info, err := gohash.NewTorrentInfo(*path, *pieceLength*1024)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+
+	v1 := hex.EncodeToString(info.InfoHashV1())
+	v2 := hex.EncodeToString(info.InfoHashV2())
+	dn := "&dn=" + strings.Replace(url.QueryEscape(info.Name), "+", "%20", -1)
+	fmt.Println("v1:", v1)
+	fmt.Println("v2:", v2)
+
+	// the v1 and v2 info dictionaries differ, so each has its own magnet link
+	fmt.Println("magnet:?xt=urn:btih:" + v1 + dn)
+	fmt.Println("magnet:?xt=urn:btmh:1220" + v2 + dn)
+
+	if *files {
+		fmt.Println("")
+		for _, f := range info.Files {
+			fmt.Printf("%x  %s\n", f.PiecesRoot, f.Path)
+		}
+	}
+}
+
+func runVerify() {
+
+	torrent, err := ioutil.ReadFile(*verify)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+	failed, err := gohash.VerifyTorrent(torrent, *path)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+	for _, name := range failed {
+		fmt.Println("FAILED", name)
+	}
+	if len(failed) > 0 {
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/kingpin"
+	"github.com/martinlindhe/gohash"
+)
+
+var (
+	path = kingpin.Arg("path", "OCI image layout directory, or docker save archive.").Required().String()
+	all  = kingpin.Flag("all", "Show all descriptors, not only mismatches.").Bool()
+)
+
+func main() {
+
+	// support -h for --help
+	kingpin.CommandLine.HelpFlag.Short('h')
+	kingpin.Parse()
+
+	root, err := gohash.VerifyImage(*path)
+	if err != nil {
+		fmt.Println("ERROR", err)
+		os.Exit(1)
+	}
+
+	failed := root.Failed()
+	if failed || *all {
+		printNode(root, "", "")
+	}
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
+
+// prints `node` and its children, which are mismatches or lead to one unless --all is set.
+// `first` prefixes the line of the node, `rest` the lines of its children
+func printNode(node *gohash.ImageNode, first, rest string) {
+	line := node.Path
+	if node.Digest != "" {
+		line = node.Digest + "  " + node.MediaType
+		if node.Size >= 0 {
+			line += fmt.Sprintf("  %d", node.Size)
+		}
+	}
+	if node.Ref != "" {
+		line += "  (" + node.Ref + ")"
+	}
+	if node.Err != nil {
+		line += "  FAILED: " + node.Err.Error()
+	}
+	fmt.Println(first + line)
+
+	var children []*gohash.ImageNode
+	for _, c := range node.Children {
+		if *all || c.Failed() {
+			children = append(children, c)
+		}
+	}
+	for i, c := range children {
+		if i == len(children)-1 {
+			printNode(c, rest+"└── ", rest+"    ")
+		} else {
+			printNode(c, rest+"├── ", rest+"│   ")
+		}
+	}
+}
@@ -39,6 +39,7 @@ var (
 		"bubblebabble": encodeBubbleBabble,
 		"binary":       encodeBinary,
 		"decimal":      encodeDecimal,
+		"eip55":        encodeEIP55,
 		"hex":          encodeHex,
 		"hexup":        encodeHexUpper,
 		"lowercase":    encodeLowercase,
@@ -61,6 +62,7 @@ var (
 		"binary":       decodeBinary,
 		"bubblebabble": decodeBubbleBabble,
 		"decimal":      decodeDecimal,
+		"eip55":        decodeEIP55,
 		"hex":          decodeHex,
 		"hexup":        decodeHex,
 		"lowercase":    encodeLowercase,
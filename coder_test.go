@@ -49,6 +49,9 @@ var (
 			fox: "84 104 101 32 113 117 105 99 107 32 98 114 111 119 110 32 102 111 120 32 106 117 109 112 115 32 111" +
 				" 118 101 114 32 116 104 101 32 108 97 122 121 32 100 111 103",
 			blank: ""},
+		"eip55": {
+			fox:   "0x54686520717569636b2062726f776e20666f78206A756D7073206F76657220746865206c617a7920646f67",
+			blank: "0x"},
 		"hex": {
 			fox:   "54686520717569636b2062726f776e20666f78206a756d7073206f76657220746865206c617a7920646f67",
 			blank: ""},
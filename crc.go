@@ -0,0 +1,423 @@
+package gohash
+
+import (
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CRCModel describes a CRC using the Rocksoft model, as used by the CRC RevEng catalogue,
+// see https://reveng.sourceforge.io/crc-catalogue/
+type CRCModel struct {
+	Name    string
+	Aliases []string
+
+	// Width is the register size in bits, 3 to 64
+	Width int
+
+	// Poly is the generator polynomial, without the top bit
+	Poly uint64
+
+	// Init is the initial register value
+	Init uint64
+
+	// RefIn is set if input bytes are processed least significant bit first
+	RefIn bool
+
+	// RefOut is set if the register is reflected before XorOut is applied
+	RefOut bool
+
+	// XorOut is xored with the final register value
+	XorOut uint64
+
+	// Check is the CRC of "123456789"
+	Check uint64
+}
+
+// crcCatalogue is the CRC RevEng catalogue of parametrised CRC algorithms, width 3 to 64
+var crcCatalogue = []CRCModel{
+	{Name: "CRC-3/GSM", Width: 3, Poly: 0x3, Init: 0x0, XorOut: 0x7, Check: 0x4},
+	{Name: "CRC-3/ROHC", Width: 3, Poly: 0x3, Init: 0x7, RefIn: true, RefOut: true, XorOut: 0x0, Check: 0x6},
+	{Name: "CRC-4/G-704", Aliases: []string{"CRC-4/ITU"}, Width: 4, Poly: 0x3, Init: 0x0, RefIn: true, RefOut: true, XorOut: 0x0, Check: 0x7},
+	{Name: "CRC-4/INTERLAKEN", Width: 4, Poly: 0x3, Init: 0xf, XorOut: 0xf, Check: 0xb},
+	{Name: "CRC-5/EPC-C1G2", Aliases: []string{"CRC-5/EPC"}, Width: 5, Poly: 0x09, Init: 0x09, XorOut: 0x00, Check: 0x00},
+	{Name: "CRC-5/G-704", Aliases: []string{"CRC-5/ITU"}, Width: 5, Poly: 0x15, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x07},
+	{Name: "CRC-5/USB", Width: 5, Poly: 0x05, Init: 0x1f, RefIn: true, RefOut: true, XorOut: 0x1f, Check: 0x19},
+	{Name: "CRC-6/CDMA2000-A", Width: 6, Poly: 0x27, Init: 0x3f, XorOut: 0x00, Check: 0x0d},
+	{Name: "CRC-6/CDMA2000-B", Width: 6, Poly: 0x07, Init: 0x3f, XorOut: 0x00, Check: 0x3b},
+	{Name: "CRC-6/DARC", Width: 6, Poly: 0x19, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x26},
+	{Name: "CRC-6/G-704", Aliases: []string{"CRC-6/ITU"}, Width: 6, Poly: 0x03, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x06},
+	{Name: "CRC-6/GSM", Width: 6, Poly: 0x2f, Init: 0x00, XorOut: 0x3f, Check: 0x13},
+	{Name: "CRC-7/MMC", Aliases: []string{"CRC-7"}, Width: 7, Poly: 0x09, Init: 0x00, XorOut: 0x00, Check: 0x75},
+	{Name: "CRC-7/ROHC", Width: 7, Poly: 0x4f, Init: 0x7f, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x53},
+	{Name: "CRC-7/UMTS", Width: 7, Poly: 0x45, Init: 0x00, XorOut: 0x00, Check: 0x61},
+	{Name: "CRC-8/AUTOSAR", Width: 8, Poly: 0x2f, Init: 0xff, XorOut: 0xff, Check: 0xdf},
+	{Name: "CRC-8/BLUETOOTH", Width: 8, Poly: 0xa7, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x26},
+	{Name: "CRC-8/CDMA2000", Width: 8, Poly: 0x9b, Init: 0xff, XorOut: 0x00, Check: 0xda},
+	{Name: "CRC-8/DARC", Width: 8, Poly: 0x39, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x15},
+	{Name: "CRC-8/DVB-S2", Width: 8, Poly: 0xd5, Init: 0x00, XorOut: 0x00, Check: 0xbc},
+	{Name: "CRC-8/GSM-A", Width: 8, Poly: 0x1d, Init: 0x00, XorOut: 0x00, Check: 0x37},
+	{Name: "CRC-8/GSM-B", Width: 8, Poly: 0x49, Init: 0x00, XorOut: 0xff, Check: 0x94},
+	{Name: "CRC-8/HITAG", Width: 8, Poly: 0x1d, Init: 0xff, XorOut: 0x00, Check: 0xb4},
+	{Name: "CRC-8/I-432-1", Aliases: []string{"CRC-8/ITU"}, Width: 8, Poly: 0x07, Init: 0x00, XorOut: 0x55, Check: 0xa1},
+	{Name: "CRC-8/I-CODE", Width: 8, Poly: 0x1d, Init: 0xfd, XorOut: 0x00, Check: 0x7e},
+	{Name: "CRC-8/LTE", Width: 8, Poly: 0x9b, Init: 0x00, XorOut: 0x00, Check: 0xea},
+	{Name: "CRC-8/MAXIM-DOW", Aliases: []string{"CRC-8/MAXIM", "DOW-CRC"}, Width: 8, Poly: 0x31, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0xa1},
+	{Name: "CRC-8/MIFARE-MAD", Width: 8, Poly: 0x1d, Init: 0xc7, XorOut: 0x00, Check: 0x99},
+	{Name: "CRC-8/NRSC-5", Width: 8, Poly: 0x31, Init: 0xff, XorOut: 0x00, Check: 0xf7},
+	{Name: "CRC-8/OPENSAFETY", Width: 8, Poly: 0x2f, Init: 0x00, XorOut: 0x00, Check: 0x3e},
+	{Name: "CRC-8/ROHC", Width: 8, Poly: 0x07, Init: 0xff, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0xd0},
+	{Name: "CRC-8/SAE-J1850", Width: 8, Poly: 0x1d, Init: 0xff, XorOut: 0xff, Check: 0x4b},
+	// "crc8-atm" was the gohash name
+	{Name: "CRC-8/SMBUS", Aliases: []string{"CRC-8", "crc8-atm"}, Width: 8, Poly: 0x07, Init: 0x00, XorOut: 0x00, Check: 0xf4},
+	{Name: "CRC-8/TECH-3250", Aliases: []string{"CRC-8/AES", "CRC-8/EBU"}, Width: 8, Poly: 0x1d, Init: 0xff, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x97},
+	{Name: "CRC-8/WCDMA", Width: 8, Poly: 0x9b, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0x25},
+	{Name: "CRC-10/ATM", Aliases: []string{"CRC-10", "CRC-10/I-610"}, Width: 10, Poly: 0x233, Init: 0x000, XorOut: 0x000, Check: 0x199},
+	{Name: "CRC-10/CDMA2000", Width: 10, Poly: 0x3d9, Init: 0x3ff, XorOut: 0x000, Check: 0x233},
+	{Name: "CRC-10/GSM", Width: 10, Poly: 0x175, Init: 0x000, XorOut: 0x3ff, Check: 0x12a},
+	{Name: "CRC-11/FLEXRAY", Aliases: []string{"CRC-11"}, Width: 11, Poly: 0x385, Init: 0x01a, XorOut: 0x000, Check: 0x5a3},
+	{Name: "CRC-11/UMTS", Width: 11, Poly: 0x307, Init: 0x000, XorOut: 0x000, Check: 0x061},
+	{Name: "CRC-12/CDMA2000", Width: 12, Poly: 0xf13, Init: 0xfff, XorOut: 0x000, Check: 0xd4d},
+	{Name: "CRC-12/DECT", Aliases: []string{"X-CRC-12"}, Width: 12, Poly: 0x80f, Init: 0x000, XorOut: 0x000, Check: 0xf5b},
+	{Name: "CRC-12/GSM", Width: 12, Poly: 0xd31, Init: 0x000, XorOut: 0xfff, Check: 0xb34},
+	{Name: "CRC-12/UMTS", Aliases: []string{"CRC-12/3GPP"}, Width: 12, Poly: 0x80f, Init: 0x000, RefOut: true, XorOut: 0x000, Check: 0xdaf},
+	{Name: "CRC-13/BBC", Width: 13, Poly: 0x1cf5, Init: 0x0000, XorOut: 0x0000, Check: 0x04fa},
+	{Name: "CRC-14/DARC", Width: 14, Poly: 0x0805, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x082d},
+	{Name: "CRC-14/GSM", Width: 14, Poly: 0x202d, Init: 0x0000, XorOut: 0x3fff, Check: 0x30ae},
+	{Name: "CRC-15/CAN", Aliases: []string{"CRC-15"}, Width: 15, Poly: 0x4599, Init: 0x0000, XorOut: 0x0000, Check: 0x059e},
+	{Name: "CRC-15/MPT1327", Width: 15, Poly: 0x6815, Init: 0x0000, XorOut: 0x0001, Check: 0x2566},
+	{Name: "CRC-16/ARC", Aliases: []string{"ARC", "CRC-16", "CRC-16/LHA", "CRC-IBM"}, Width: 16, Poly: 0x8005, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0xbb3d},
+	{Name: "CRC-16/CDMA2000", Width: 16, Poly: 0xc867, Init: 0xffff, XorOut: 0x0000, Check: 0x4c06},
+	{Name: "CRC-16/CMS", Width: 16, Poly: 0x8005, Init: 0xffff, XorOut: 0x0000, Check: 0xaee7},
+	{Name: "CRC-16/DDS-110", Width: 16, Poly: 0x8005, Init: 0x800d, XorOut: 0x0000, Check: 0x9ecf},
+	{Name: "CRC-16/DECT-R", Aliases: []string{"R-CRC-16"}, Width: 16, Poly: 0x0589, Init: 0x0000, XorOut: 0x0001, Check: 0x007e},
+	{Name: "CRC-16/DECT-X", Aliases: []string{"X-CRC-16"}, Width: 16, Poly: 0x0589, Init: 0x0000, XorOut: 0x0000, Check: 0x007f},
+	{Name: "CRC-16/DNP", Width: 16, Poly: 0x3d65, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0xffff, Check: 0xea82},
+	{Name: "CRC-16/EN-13757", Width: 16, Poly: 0x3d65, Init: 0x0000, XorOut: 0xffff, Check: 0xc2b7},
+	{Name: "CRC-16/GENIBUS", Aliases: []string{"CRC-16/DARC", "CRC-16/EPC", "CRC-16/EPC-C1G2", "CRC-16/I-CODE"}, Width: 16, Poly: 0x1021, Init: 0xffff, XorOut: 0xffff, Check: 0xd64e},
+	{Name: "CRC-16/GSM", Width: 16, Poly: 0x1021, Init: 0x0000, XorOut: 0xffff, Check: 0xce3c},
+	// "crc16-ccitt-false" was the gohash name
+	{Name: "CRC-16/IBM-3740", Aliases: []string{"CRC-16/AUTOSAR", "CRC-16/CCITT-FALSE", "crc16-ccitt-false"}, Width: 16, Poly: 0x1021, Init: 0xffff, XorOut: 0x0000, Check: 0x29b1},
+	// "crc16-ccitt" was the gohash name
+	{Name: "CRC-16/IBM-SDLC", Aliases: []string{"CRC-16/ISO-HDLC", "CRC-16/ISO-IEC-14443-3-B", "CRC-16/X-25", "CRC-B", "X-25", "crc16-ccitt"}, Width: 16, Poly: 0x1021, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0xffff, Check: 0x906e},
+	{Name: "CRC-16/ISO-IEC-14443-3-A", Aliases: []string{"CRC-A"}, Width: 16, Poly: 0x1021, Init: 0xc6c6, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0xbf05},
+	{Name: "CRC-16/KERMIT", Aliases: []string{"CRC-16/BLUETOOTH", "CRC-16/CCITT", "CRC-16/CCITT-TRUE", "CRC-16/V-41-LSB", "CRC-CCITT", "KERMIT"}, Width: 16, Poly: 0x1021, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x2189},
+	{Name: "CRC-16/LJ1200", Width: 16, Poly: 0x6f63, Init: 0x0000, XorOut: 0x0000, Check: 0xbdf4},
+	{Name: "CRC-16/M17", Width: 16, Poly: 0x5935, Init: 0xffff, XorOut: 0x0000, Check: 0x772b},
+	{Name: "CRC-16/MAXIM-DOW", Aliases: []string{"CRC-16/MAXIM"}, Width: 16, Poly: 0x8005, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0xffff, Check: 0x44c2},
+	{Name: "CRC-16/MCRF4XX", Width: 16, Poly: 0x1021, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x6f91},
+	{Name: "CRC-16/MODBUS", Aliases: []string{"MODBUS"}, Width: 16, Poly: 0x8005, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x4b37},
+	{Name: "CRC-16/NRSC-5", Width: 16, Poly: 0x080b, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0xa066},
+	{Name: "CRC-16/OPENSAFETY-A", Width: 16, Poly: 0x5935, Init: 0x0000, XorOut: 0x0000, Check: 0x5d38},
+	{Name: "CRC-16/OPENSAFETY-B", Width: 16, Poly: 0x755b, Init: 0x0000, XorOut: 0x0000, Check: 0x20fe},
+	{Name: "CRC-16/PROFIBUS", Aliases: []string{"CRC-16/IEC-61158-2"}, Width: 16, Poly: 0x1dcf, Init: 0xffff, XorOut: 0xffff, Check: 0xa819},
+	{Name: "CRC-16/RIELLO", Width: 16, Poly: 0x1021, Init: 0xb2aa, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x63d0},
+	{Name: "CRC-16/SPI-FUJITSU", Aliases: []string{"CRC-16/AUG-CCITT"}, Width: 16, Poly: 0x1021, Init: 0x1d0f, XorOut: 0x0000, Check: 0xe5cc},
+	{Name: "CRC-16/T10-DIF", Width: 16, Poly: 0x8bb7, Init: 0x0000, XorOut: 0x0000, Check: 0xd0db},
+	{Name: "CRC-16/TELEDISK", Width: 16, Poly: 0xa097, Init: 0x0000, XorOut: 0x0000, Check: 0x0fb3},
+	{Name: "CRC-16/TMS37157", Width: 16, Poly: 0x1021, Init: 0x89ec, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x26b1},
+	{Name: "CRC-16/UMTS", Aliases: []string{"CRC-16/BUYPASS", "CRC-16/VERIFONE"}, Width: 16, Poly: 0x8005, Init: 0x0000, XorOut: 0x0000, Check: 0xfee8},
+	// "crc16-ibm" was the gohash name
+	{Name: "CRC-16/USB", Aliases: []string{"crc16-ibm"}, Width: 16, Poly: 0x8005, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0xffff, Check: 0xb4c8},
+	{Name: "CRC-16/XMODEM", Aliases: []string{"CRC-16/ACORN", "CRC-16/LTE", "CRC-16/V-41-MSB", "XMODEM", "ZMODEM"}, Width: 16, Poly: 0x1021, Init: 0x0000, XorOut: 0x0000, Check: 0x31c3},
+	{Name: "CRC-17/CAN-FD", Width: 17, Poly: 0x1685b, Init: 0x00000, XorOut: 0x00000, Check: 0x04f03},
+	{Name: "CRC-21/CAN-FD", Width: 21, Poly: 0x102899, Init: 0x000000, XorOut: 0x000000, Check: 0x0ed841},
+	{Name: "CRC-24/BLE", Width: 24, Poly: 0x00065b, Init: 0x555555, RefIn: true, RefOut: true, XorOut: 0x000000, Check: 0xc25a56},
+	{Name: "CRC-24/FLEXRAY-A", Width: 24, Poly: 0x5d6dcb, Init: 0xfedcba, XorOut: 0x000000, Check: 0x7979bd},
+	{Name: "CRC-24/FLEXRAY-B", Width: 24, Poly: 0x5d6dcb, Init: 0xabcdef, XorOut: 0x000000, Check: 0x1f23b8},
+	{Name: "CRC-24/INTERLAKEN", Width: 24, Poly: 0x328b63, Init: 0xffffff, XorOut: 0xffffff, Check: 0xb4f3e6},
+	{Name: "CRC-24/LTE-A", Width: 24, Poly: 0x864cfb, Init: 0x000000, XorOut: 0x000000, Check: 0xcde703},
+	{Name: "CRC-24/LTE-B", Width: 24, Poly: 0x800063, Init: 0x000000, XorOut: 0x000000, Check: 0x23ef52},
+	// "crc24-openpgp" was the gohash name
+	{Name: "CRC-24/OPENPGP", Aliases: []string{"CRC-24", "crc24-openpgp"}, Width: 24, Poly: 0x864cfb, Init: 0xb704ce, XorOut: 0x000000, Check: 0x21cf02},
+	{Name: "CRC-24/OS-9", Width: 24, Poly: 0x800063, Init: 0xffffff, XorOut: 0xffffff, Check: 0x200fa5},
+	{Name: "CRC-30/CDMA", Width: 30, Poly: 0x2030b9c7, Init: 0x3fffffff, XorOut: 0x3fffffff, Check: 0x04c34abf},
+	{Name: "CRC-31/PHILIPS", Width: 31, Poly: 0x04c11db7, Init: 0x7fffffff, XorOut: 0x7fffffff, Check: 0x0ce9e46c},
+	{Name: "CRC-32/AIXM", Aliases: []string{"CRC-32Q"}, Width: 32, Poly: 0x814141ab, Init: 0x00000000, XorOut: 0x00000000, Check: 0x3010bf7f},
+	{Name: "CRC-32/AUTOSAR", Width: 32, Poly: 0xf4acfb13, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0x1697d06a},
+	{Name: "CRC-32/BASE91-D", Aliases: []string{"CRC-32D"}, Width: 32, Poly: 0xa833982b, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0x87315576},
+	{Name: "CRC-32/BZIP2", Aliases: []string{"CRC-32/AAL5", "CRC-32/DECT-B", "B-CRC-32"}, Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, XorOut: 0xffffffff, Check: 0xfc891918},
+	{Name: "CRC-32/CD-ROM-EDC", Width: 32, Poly: 0x8001801b, Init: 0x00000000, RefIn: true, RefOut: true, XorOut: 0x00000000, Check: 0x6ec2edc4},
+	{Name: "CRC-32/CKSUM", Aliases: []string{"CKSUM", "CRC-32/POSIX"}, Width: 32, Poly: 0x04c11db7, Init: 0x00000000, XorOut: 0xffffffff, Check: 0x765e7680},
+	// "crc32-castagnoli" and "crc32c" were the gohash names
+	{Name: "CRC-32/ISCSI", Aliases: []string{"CRC-32/BASE91-C", "CRC-32/CASTAGNOLI", "CRC-32/INTERLAKEN", "CRC-32C", "CRC-32/NVME", "crc32-castagnoli", "crc32c"}, Width: 32, Poly: 0x1edc6f41, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0xe3069283},
+	// "crc32-ieee" and "crc32" were the gohash names
+	{Name: "CRC-32/ISO-HDLC", Aliases: []string{"CRC-32", "CRC-32/ADCCP", "CRC-32/V-42", "CRC-32/XZ", "PKZIP", "crc32-ieee", "crc32"}, Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0xcbf43926},
+	{Name: "CRC-32/JAMCRC", Aliases: []string{"JAMCRC"}, Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0x00000000, Check: 0x340bc6d9},
+	{Name: "CRC-32/MEF", Width: 32, Poly: 0x741b8cd7, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0x00000000, Check: 0xd2c22f51},
+	{Name: "CRC-32/MPEG-2", Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, XorOut: 0x00000000, Check: 0x0376e6e7},
+	{Name: "CRC-32/XFER", Aliases: []string{"XFER"}, Width: 32, Poly: 0x000000af, Init: 0x00000000, XorOut: 0x00000000, Check: 0xbd0be338},
+	{Name: "CRC-40/GSM", Width: 40, Poly: 0x0004820009, Init: 0x0000000000, XorOut: 0xffffffffff, Check: 0xd4164fc646},
+	{Name: "CRC-64/ECMA-182", Aliases: []string{"CRC-64"}, Width: 64, Poly: 0x42f0e1eba9ea3693, Init: 0x0000000000000000, XorOut: 0x0000000000000000, Check: 0x6c40df5f0b497347},
+	// "crc64-iso" was the gohash name
+	{Name: "CRC-64/GO-ISO", Aliases: []string{"crc64-iso"}, Width: 64, Poly: 0x000000000000001b, Init: 0xffffffffffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffffffffffff, Check: 0xb90956c775a41001},
+	{Name: "CRC-64/MS", Width: 64, Poly: 0x259c84cba6426349, Init: 0xffffffffffffffff, RefIn: true, RefOut: true, XorOut: 0x0000000000000000, Check: 0x75d4b74f024eceea},
+	{Name: "CRC-64/NVME", Width: 64, Poly: 0xad93d23594c93659, Init: 0xffffffffffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffffffffffff, Check: 0xae8b14860a799888},
+	{Name: "CRC-64/REDIS", Width: 64, Poly: 0xad93d23594c935a9, Init: 0x0000000000000000, RefIn: true, RefOut: true, XorOut: 0x0000000000000000, Check: 0xe9c6d914c4b8d9ca},
+	{Name: "CRC-64/WE", Width: 64, Poly: 0x42f0e1eba9ea3693, Init: 0xffffffffffffffff, XorOut: 0xffffffffffffffff, Check: 0x62ec59e3f1a4f00a},
+	// "crc64-ecma" was the gohash name
+	{Name: "CRC-64/XZ", Aliases: []string{"CRC-64/GO-ECMA", "crc64-ecma"}, Width: 64, Poly: 0x42f0e1eba9ea3693, Init: 0xffffffffffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffffffffffff, Check: 0x995dc9bbdf1939fa},
+}
+
+// crcModels are CRC algorithms not in the CRC RevEng catalogue
+var crcModels = []CRCModel{
+	// "crc16-scsi" is CRC-16/T10-DIF processed least significant bit first
+	{Name: "crc16-scsi", Width: 16, Poly: 0x8bb7, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0xffff, Check: 0x216b},
+	// "crc32-koopman" is CRC-32/MEF with a final xor
+	{Name: "crc32-koopman", Aliases: []string{"crc32k"}, Width: 32, Poly: 0x741b8cd7, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0x2d3dd0ae},
+}
+
+func init() {
+	for _, models := range [][]CRCModel{crcCatalogue, crcModels} {
+		for _, model := range models {
+			mustRegisterHash(model.hashAlgorithm())
+		}
+	}
+}
+
+// CRCModels returns the CRC RevEng catalogue, followed by other known CRC algorithms
+func CRCModels() []CRCModel {
+	res := append([]CRCModel{}, crcCatalogue...)
+	return append(res, crcModels...)
+}
+
+// lookupCRCModel returns the known model named `name`
+func lookupCRCModel(name string) (CRCModel, bool) {
+	for _, m := range CRCModels() {
+		if strings.EqualFold(m.Name, name) {
+			return m, true
+		}
+	}
+	return CRCModel{}, false
+}
+
+func (m CRCModel) hashAlgorithm() HashAlgorithm {
+	return HashAlgorithm{
+		Name:    m.Name,
+		Aliases: m.Aliases,
+		Bits:    m.size() * 8,
+		Family:  "crc",
+		New: func() hash.Hash {
+			h, _ := NewCRC(m)
+			return h
+		},
+		NewWithParams: func(p HashParams) (hash.Hash, error) {
+			model, err := m.withParams(p)
+			if err != nil {
+				return nil, err
+			}
+			return NewCRC(model)
+		},
+	}
+}
+
+// size returns the checksum size in bytes
+func (m CRCModel) size() int {
+	return (m.Width + 7) / 8
+}
+
+func (m CRCModel) mask() uint64 {
+	return ^uint64(0) >> uint(64-m.Width)
+}
+
+// returns the model with parameters width, poly, init, refin, refout and xorout replaced.
+// Values are hex encoded, "0x" prefix is optional
+func (m CRCModel) withParams(p HashParams) (CRCModel, error) {
+	if err := p.allow("width", "poly", "init", "refin", "refout", "xorout"); err != nil {
+		return m, err
+	}
+	var err error
+	if m.Width, err = p.int("width", m.Width); err != nil {
+		return m, err
+	}
+	for key, val := range map[string]*uint64{"poly": &m.Poly, "init": &m.Init, "xorout": &m.XorOut} {
+		s, ok := p[key]
+		if !ok {
+			continue
+		}
+		if *val, err = strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 64); err != nil {
+			return m, fmt.Errorf("parameter %s must be hex encoded: %v", key, err)
+		}
+	}
+	for key, val := range map[string]*bool{"refin": &m.RefIn, "refout": &m.RefOut} {
+		s, ok := p[key]
+		if !ok {
+			continue
+		}
+		if *val, err = strconv.ParseBool(s); err != nil {
+			return m, fmt.Errorf("parameter %s must be true or false: %v", key, err)
+		}
+	}
+	return m, nil
+}
+
+// NewCRC returns a new hash.Hash computing the CRC described by `model`.
+// The checksum is big endian, using the smallest number of bytes holding Width bits
+func NewCRC(model CRCModel) (hash.Hash, error) {
+	if model.Width < 3 || model.Width > 64 {
+		return nil, fmt.Errorf("crc width must be 3 to 64, is %d", model.Width)
+	}
+	mask := model.mask()
+	if model.Poly&mask != model.Poly || model.Init&mask != model.Init || model.XorOut&mask != model.XorOut {
+		return nil, fmt.Errorf("crc parameters must fit in %d bits", model.Width)
+	}
+	h := &crcHash{model: model, table: crcTable(model.Width, model.Poly, model.RefIn)}
+	if model.RefIn && model.Width == 32 {
+		h.table32 = crc32Table(uint32(reflectBits(model.Poly, 32)))
+	}
+	if model.RefIn && model.Width == 64 {
+		h.table64 = crc64Table(reflectBits(model.Poly, 64))
+	}
+	h.Reset()
+	return h, nil
+}
+
+// crcHash is a table driven CRC processing a byte at a time. The register is
+// kept reflected if RefIn is set, else aligned to the top of the uint64
+type crcHash struct {
+	model   CRCModel
+	table   *[256]uint64
+	table32 *crc32.Table
+	table64 *crc64.Table
+	reg     uint64
+}
+
+func (h *crcHash) Reset() {
+	if h.model.RefIn {
+		h.reg = reflectBits(h.model.Init, h.model.Width)
+	} else {
+		h.reg = h.model.Init << uint(64-h.model.Width)
+	}
+}
+
+func (h *crcHash) Size() int      { return h.model.size() }
+func (h *crcHash) BlockSize() int { return 1 }
+
+func (h *crcHash) Write(p []byte) (int, error) {
+	switch {
+	case h.table32 != nil:
+		// hash/crc32 inverts the register before and after
+		h.reg = uint64(^crc32.Update(^uint32(h.reg), h.table32, p))
+	case h.table64 != nil:
+		h.reg = ^crc64.Update(^h.reg, h.table64, p)
+	case h.model.RefIn:
+		for _, b := range p {
+			h.reg = h.table[byte(h.reg)^b] ^ h.reg>>8
+		}
+	default:
+		for _, b := range p {
+			h.reg = h.table[byte(h.reg>>56)^b] ^ h.reg<<8
+		}
+	}
+	return len(p), nil
+}
+
+// Sum64 returns the CRC
+func (h *crcHash) Sum64() uint64 {
+	crc := h.reg
+	if !h.model.RefIn {
+		crc >>= uint(64 - h.model.Width)
+	}
+	if h.model.RefIn != h.model.RefOut {
+		crc = reflectBits(crc, h.model.Width)
+	}
+	return (crc ^ h.model.XorOut) & h.model.mask()
+}
+
+func (h *crcHash) Sum(b []byte) []byte {
+	crc := h.Sum64()
+	for i := h.Size() - 1; i >= 0; i-- {
+		b = append(b, byte(crc>>uint(8*i)))
+	}
+	return b
+}
+
+// reflectBits returns the lowest `width` bits of `v` in reverse order
+func reflectBits(v uint64, width int) uint64 {
+	return bits.Reverse64(v) >> uint(64-width)
+}
+
+type crcTableKey struct {
+	width int
+	poly  uint64
+	refIn bool
+}
+
+var (
+	crcTablesMutex = &sync.Mutex{}
+	crcTables      = map[crcTableKey]*[256]uint64{}
+	crc32Tables    = map[uint32]*crc32.Table{}
+	crc64Tables    = map[uint64]*crc64.Table{}
+)
+
+// returns the byte table for the CRC, tables are cached as they are
+// costly to create compared to hashing short inputs
+func crcTable(width int, poly uint64, refIn bool) *[256]uint64 {
+	crcTablesMutex.Lock()
+	defer crcTablesMutex.Unlock()
+
+	key := crcTableKey{width, poly, refIn}
+	if t, ok := crcTables[key]; ok {
+		return t
+	}
+	t := &[256]uint64{}
+	if refIn {
+		rpoly := reflectBits(poly, width)
+		for i := range t {
+			c := uint64(i)
+			for j := 0; j < 8; j++ {
+				if c&1 != 0 {
+					c = c>>1 ^ rpoly
+				} else {
+					c >>= 1
+				}
+			}
+			t[i] = c
+		}
+	} else {
+		tpoly := poly << uint(64-width)
+		for i := range t {
+			c := uint64(i) << 56
+			for j := 0; j < 8; j++ {
+				if c&(1<<63) != 0 {
+					c = c<<1 ^ tpoly
+				} else {
+					c <<= 1
+				}
+			}
+			t[i] = c
+		}
+	}
+	crcTables[key] = t
+	return t
+}
+
+// hash/crc32 and hash/crc64 are used for reflected 32 and 64 bit CRC:s, as they are faster
+func crc32Table(rpoly uint32) *crc32.Table {
+	crcTablesMutex.Lock()
+	defer crcTablesMutex.Unlock()
+
+	if t, ok := crc32Tables[rpoly]; ok {
+		return t
+	}
+	t := crc32.MakeTable(rpoly)
+	crc32Tables[rpoly] = t
+	return t
+}
+
+func crc64Table(rpoly uint64) *crc64.Table {
+	crcTablesMutex.Lock()
+	defer crcTablesMutex.Unlock()
+
+	if t, ok := crc64Tables[rpoly]; ok {
+		return t
+	}
+	t := crc64.MakeTable(rpoly)
+	crc64Tables[rpoly] = t
+	return t
+}
@@ -0,0 +1,95 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// crcBitwise is a bit at a time reference implementation of the Rocksoft model
+func crcBitwise(m CRCModel, data []byte) uint64 {
+	top := uint64(1) << uint(m.Width-1)
+	reg := m.Init
+	for _, b := range data {
+		if m.RefIn {
+			b = byte(reflectBits(uint64(b), 8))
+		}
+		for i := 7; i >= 0; i-- {
+			feedback := reg&top != 0
+			if b>>uint(i)&1 == 1 {
+				feedback = !feedback
+			}
+			reg = reg << 1 & m.mask()
+			if feedback {
+				reg ^= m.Poly
+			}
+		}
+	}
+	if m.RefOut {
+		reg = reflectBits(reg, m.Width)
+	}
+	return reg ^ m.XorOut
+}
+
+func TestCRCModels(t *testing.T) {
+	data := make([]byte, 300)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	for _, m := range CRCModels() {
+		assert.Equal(t, m.Check, crcBitwise(m, []byte("123456789")), m.Name+" reference")
+
+		// catalogue names are found as written and in lower case
+		for _, id := range append([]string{m.Name, strings.ToLower(m.Name)}, m.Aliases...) {
+			algo, ok := LookupHash(id)
+			assert.Equal(t, true, ok, id)
+			assert.Equal(t, strings.ToLower(m.Name), algo.Name, id)
+
+			h, err := NewHash(id)
+			assert.Equal(t, nil, err, id)
+			_, _ = h.Write([]byte("123456789"))
+			assert.Equal(t, m.Check, h.(*crcHash).Sum64(), id)
+			assert.Equal(t, (m.Width+7)/8, len(h.Sum(nil)), id)
+		}
+
+		// compare the table driven implementation on longer input, written in pieces
+		h, _ := NewCRC(m)
+		_, _ = h.Write(data[:7])
+		_, _ = h.Write(data[7:])
+		assert.Equal(t, crcBitwise(m, data), h.(*crcHash).Sum64(), m.Name)
+	}
+}
+
+func TestCRCParams(t *testing.T) {
+	tests := []struct {
+		algo     string
+		expected string
+	}{
+		{"crc-16/arc:init=ffff", "4b37"},
+		{"crc-32/iso-hdlc:width=16,poly=1021,init=0xffff,refin=false,refout=false,xorout=0", "29b1"},
+		{"crc-8/smbus:width=5,poly=15,refin=true,refout=true", "07"},
+		{"crc-64/xz:xorout=0", "66a2364420e6c605"},
+	}
+	for _, test := range tests {
+		calc := NewCalculator(strings.NewReader("123456789"))
+		res, err := calc.Sum(test.algo)
+		assert.Equal(t, nil, err, test.algo)
+		assert.Equal(t, test.expected, hex.EncodeToString(res), test.algo)
+	}
+
+	invalid := []string{
+		"crc-16/arc:width=2",
+		"crc-16/arc:width=65",
+		"crc-16/arc:init=10000",
+		"crc-16/arc:poly=xyz",
+		"crc-16/arc:refin=maybe",
+		"crc-16/arc:check=0",
+		"crc-32/iso-hdlc:width=16",
+	}
+	for _, algo := range invalid {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+}
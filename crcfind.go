@@ -0,0 +1,401 @@
+package gohash
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sort"
+)
+
+// CRCFinder is used to find the CRC model of messages with known checksums,
+// by searching the width, polynomial, initial value, reflection and final xor
+type CRCFinder struct {
+	samples []crcSample
+	width   int
+}
+
+type crcSample struct {
+	message []byte
+	crc     uint64
+	size    int
+}
+
+const (
+	// the polynomial search gives up if more than this many bits are left to factor
+	crcMaxCofactorDegree = 16
+)
+
+// NewCRCFinder returns a new CRCFinder
+func NewCRCFinder() *CRCFinder {
+	return &CRCFinder{}
+}
+
+// AddSample adds a message and its big endian checksum
+func (f *CRCFinder) AddSample(message, checksum []byte) error {
+	if len(checksum) == 0 || len(checksum) > 8 {
+		return fmt.Errorf("checksum must be 1 to 8 bytes, is %d", len(checksum))
+	}
+	if len(f.samples) > 0 && f.samples[0].size != len(checksum) {
+		return fmt.Errorf("checksums must be the same size")
+	}
+	var crc uint64
+	for _, b := range checksum {
+		crc = crc<<8 | uint64(b)
+	}
+	f.samples = append(f.samples, crcSample{message: message, crc: crc, size: len(checksum)})
+	return nil
+}
+
+// Width sets the CRC width in bits. By default all widths fitting the checksums are searched
+func (f *CRCFinder) Width(width int) { f.width = width }
+
+// Find returns all CRC models matching the samples, known models first.
+// The polynomial search needs at least two messages of the same length, with
+// more messages of different lengths needed to tell the initial value from the final xor
+func (f *CRCFinder) Find() ([]CRCModel, error) {
+
+	if len(f.samples) == 0 {
+		return nil, fmt.Errorf("no samples")
+	}
+
+	widths, err := f.widths()
+	if err != nil {
+		return nil, err
+	}
+
+	res := []CRCModel{}
+	for _, m := range CRCModels() {
+		if isIntInSlice(m.Width, widths) && f.matches(m) {
+			res = append(res, m)
+		}
+	}
+
+	if !f.hasPair() {
+		if len(res) > 0 {
+			return res, nil
+		}
+		return nil, fmt.Errorf("no known model matches, at least two messages of the same length are needed to search")
+	}
+
+	for _, width := range widths {
+		for _, refIn := range []bool{false, true} {
+			for _, refOut := range []bool{false, true} {
+				polys, err := f.searchPolys(width, refIn, refOut)
+				if err != nil {
+					return nil, err
+				}
+				for _, poly := range polys {
+					for _, m := range f.solve(CRCModel{Width: width, Poly: poly, RefIn: refIn, RefOut: refOut}) {
+						if !containsCRCModel(res, m) {
+							res = append(res, m)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Name != "" && res[j].Name == ""
+	})
+	return res, nil
+}
+
+// returns the widths to search, largest first
+func (f *CRCFinder) widths() ([]int, error) {
+	var max uint64
+	for _, s := range f.samples {
+		if s.crc > max {
+			max = s.crc
+		}
+	}
+	need := bits.Len64(max)
+	if f.width != 0 {
+		if f.width < 3 || f.width > 64 {
+			return nil, fmt.Errorf("crc width must be 3 to 64, is %d", f.width)
+		}
+		if f.width < need || f.width > f.samples[0].size*8 {
+			return nil, fmt.Errorf("checksums dont fit width %d", f.width)
+		}
+		return []int{f.width}, nil
+	}
+	res := []int{}
+	for w := f.samples[0].size * 8; w >= 3 && w > f.samples[0].size*8-8; w-- {
+		if w >= need {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
+
+func (f *CRCFinder) hasPair() bool {
+	lengths := map[int]bool{}
+	for _, s := range f.samples {
+		if lengths[len(s.message)] {
+			return true
+		}
+		lengths[len(s.message)] = true
+	}
+	return false
+}
+
+// returns true if `m` gives the checksum of all samples
+func (f *CRCFinder) matches(m CRCModel) bool {
+	for _, s := range f.samples {
+		if crcChecksum(m, s.message) != s.crc {
+			return false
+		}
+	}
+	return true
+}
+
+func crcChecksum(m CRCModel, data []byte) uint64 {
+	h, _ := NewCRC(m)
+	_, _ = h.Write(data)
+	return h.(*crcHash).Sum64()
+}
+
+// searchPolys returns the polynomials of `width` dividing the difference of all
+// pairs of samples with the same message length. The initial value and final
+// xor cancel out, so the difference is a plain polynomial division:
+//
+//	(M1(x) + M2(x)) * x^width + (R1(x) + R2(x)) = 0 mod P(x)
+func (f *CRCFinder) searchPolys(width int, refIn, refOut bool) ([]uint64, error) {
+	g := new(big.Int)
+	byLength := map[int]crcSample{}
+	for _, s := range f.samples {
+		first, ok := byLength[len(s.message)]
+		if !ok {
+			byLength[len(s.message)] = s
+			continue
+		}
+		d := new(big.Int).Xor(gf2Message(first.message, refIn), gf2Message(s.message, refIn))
+		d.Lsh(d, uint(width))
+		r := first.crc ^ s.crc
+		if refOut {
+			r = reflectBits(r, width)
+		}
+		d.Xor(d, new(big.Int).SetUint64(r))
+		g = gf2GCD(g, d)
+	}
+
+	if g.Sign() == 0 {
+		// identical messages and checksums only
+		return nil, fmt.Errorf("at least two different messages of the same length are needed")
+	}
+	k := g.BitLen() - 1 - width
+	if k < 0 {
+		return nil, nil
+	}
+	if k > crcMaxCofactorDegree {
+		return nil, fmt.Errorf("not enough samples to find a %d bit polynomial, add more messages of the same length", width)
+	}
+
+	// P(x) * Q(x) = G(x), try all Q(x) of degree k
+	res := []uint64{}
+	for q := uint64(0); q < 1<<uint(k); q++ {
+		quotient, rem := gf2DivMod(g, new(big.Int).SetUint64(1<<uint(k)|q))
+		if rem.Sign() == 0 && quotient.BitLen() == width+1 {
+			res = append(res, quotient.Uint64()&(^uint64(0)>>uint(64-width)))
+		}
+	}
+	return res, nil
+}
+
+// solve finds the initial values and final xors of `m` matching the samples.
+// The checksum is linear in both, so they are found by gaussian elimination.
+// If the samples can't tell them apart, the solutions with an initial value or
+// final xor of all zeros or all ones are returned
+func (f *CRCFinder) solve(m CRCModel) []CRCModel {
+	// the unknowns are a = initial value and b = final xor
+	mask := m.mask()
+	rows := []gf2Row{}
+	for _, s := range f.samples {
+		base := crcChecksum(m, s.message)
+		initCols := make([]uint64, m.Width)
+		for j := 0; j < m.Width; j++ {
+			mj := m
+			mj.Init = 1 << uint(j)
+			initCols[j] = crcChecksum(mj, s.message) ^ base
+		}
+		rhs := s.crc ^ base
+		for i := 0; i < m.Width; i++ {
+			row := gf2Row{rhs: rhs>>uint(i)&1 == 1}
+			for j := 0; j < m.Width; j++ {
+				if initCols[j]>>uint(i)&1 == 1 {
+					row.a |= 1 << uint(j)
+				}
+			}
+			row.b = 1 << uint(i)
+			rows = append(rows, row)
+		}
+	}
+
+	if init, xorOut, free, ok := gf2Solve(rows, m.Width, m.Width); ok && free == 0 {
+		m.Init, m.XorOut = init, xorOut
+		return []CRCModel{f.named(m)}
+	}
+
+	res := []CRCModel{}
+	for _, guess := range []gf2Row{{a: mask}, {b: mask}} {
+		for _, value := range []uint64{0, mask} {
+			fixed := append([]gf2Row{}, rows...)
+			for i := 0; i < m.Width; i++ {
+				fixed = append(fixed, gf2Row{
+					a:   guess.a & (1 << uint(i)),
+					b:   guess.b & (1 << uint(i)),
+					rhs: value>>uint(i)&1 == 1,
+				})
+			}
+			if init, xorOut, free, ok := gf2Solve(fixed, m.Width, m.Width); ok && free == 0 {
+				m.Init, m.XorOut = init, xorOut
+				if !containsCRCModel(res, m) {
+					res = append(res, f.named(m))
+				}
+			}
+		}
+	}
+	return res
+}
+
+// returns `m` with name and check value of the known model with the same parameters
+func (f *CRCFinder) named(m CRCModel) CRCModel {
+	for _, known := range CRCModels() {
+		if known.sameParams(m) {
+			return known
+		}
+	}
+	m.Check = crcChecksum(m, []byte("123456789"))
+	return m
+}
+
+func (m CRCModel) sameParams(o CRCModel) bool {
+	return m.Width == o.Width && m.Poly == o.Poly && m.Init == o.Init &&
+		m.RefIn == o.RefIn && m.RefOut == o.RefOut && m.XorOut == o.XorOut
+}
+
+func containsCRCModel(models []CRCModel, m CRCModel) bool {
+	for _, o := range models {
+		if o.sameParams(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the model in the format used by the CRC RevEng catalogue
+func (m CRCModel) String() string {
+	digits := (m.Width + 3) / 4
+	name := "(none)"
+	if m.Name != "" {
+		name = `"` + m.Name + `"`
+	}
+	return fmt.Sprintf("width=%d  poly=0x%0*x  init=0x%0*x  refin=%t  refout=%t  xorout=0x%0*x  check=0x%0*x  name=%s",
+		m.Width, digits, m.Poly, digits, m.Init, m.RefIn, m.RefOut, digits, m.XorOut, digits, m.Check, name)
+}
+
+// gf2Row is an equation over GF(2) in the bits of two unknowns `a` and `b`
+type gf2Row struct {
+	a   uint64
+	b   uint64
+	rhs bool
+}
+
+// gf2Solve solves `rows` for the lowest `widthA` bits of a and `widthB` bits of b
+// by gaussian elimination, returning a solution with all free variables set to zero
+// and the number of free variables
+func gf2Solve(rows []gf2Row, widthA, widthB int) (a, b uint64, free int, ok bool) {
+	rows = append([]gf2Row{}, rows...)
+	type pivot struct {
+		row int
+		isA bool
+		bit uint64
+	}
+	pivots := []pivot{}
+	r := 0
+	for v := 0; v < widthA+widthB; v++ {
+		isA := v < widthA
+		bit := uint64(1) << uint(v)
+		if !isA {
+			bit = 1 << uint(v-widthA)
+		}
+		get := func(row gf2Row) bool {
+			if isA {
+				return row.a&bit != 0
+			}
+			return row.b&bit != 0
+		}
+		p := -1
+		for i := r; i < len(rows); i++ {
+			if get(rows[i]) {
+				p = i
+				break
+			}
+		}
+		if p == -1 {
+			free++
+			continue
+		}
+		rows[r], rows[p] = rows[p], rows[r]
+		for i := range rows {
+			if i != r && get(rows[i]) {
+				rows[i].a ^= rows[r].a
+				rows[i].b ^= rows[r].b
+				rows[i].rhs = rows[i].rhs != rows[r].rhs
+			}
+		}
+		pivots = append(pivots, pivot{r, isA, bit})
+		r++
+	}
+	for i := r; i < len(rows); i++ {
+		if rows[i].rhs {
+			return 0, 0, 0, false
+		}
+	}
+	for _, p := range pivots {
+		if !rows[p.row].rhs {
+			continue
+		}
+		if p.isA {
+			a |= p.bit
+		} else {
+			b |= p.bit
+		}
+	}
+	return a, b, free, true
+}
+
+// gf2Message returns the message as a polynomial over GF(2), first bit highest
+func gf2Message(message []byte, refIn bool) *big.Int {
+	b := make([]byte, len(message))
+	for i, c := range message {
+		if refIn {
+			c = bits.Reverse8(c)
+		}
+		b[i] = c
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+// gf2DivMod divides polynomials over GF(2)
+func gf2DivMod(a, b *big.Int) (*big.Int, *big.Int) {
+	q := new(big.Int)
+	r := new(big.Int).Set(a)
+	t := new(big.Int)
+	for r.BitLen() >= b.BitLen() {
+		shift := uint(r.BitLen() - b.BitLen())
+		r.Xor(r, t.Lsh(b, shift))
+		q.SetBit(q, int(shift), 1)
+	}
+	return q, r
+}
+
+// gf2GCD returns the greatest common divisor of polynomials over GF(2)
+func gf2GCD(a, b *big.Int) *big.Int {
+	a, b = new(big.Int).Set(a), new(big.Int).Set(b)
+	for b.Sign() != 0 {
+		_, r := gf2DivMod(a, b)
+		a, b = b, r
+	}
+	return a
+}
@@ -0,0 +1,86 @@
+package gohash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// returns a CRCFinder with `n` random messages of each length in `lengths` and their checksums
+func newTestCRCFinder(m CRCModel, n int, lengths ...int) *CRCFinder {
+	rnd := rand.New(rand.NewSource(1))
+	f := NewCRCFinder()
+	for _, length := range lengths {
+		for i := 0; i < n; i++ {
+			msg := make([]byte, length)
+			rnd.Read(msg)
+			h, _ := NewCRC(m)
+			_, _ = h.Write(msg)
+			_ = f.AddSample(msg, h.Sum(nil))
+		}
+	}
+	return f
+}
+
+func TestCRCFinder(t *testing.T) {
+	custom := CRCModel{Width: 13, Poly: 0x1d0f, Init: 0x0123, RefIn: true, RefOut: true, XorOut: 0x1abc}
+	custom.Check = crcChecksum(custom, []byte("123456789"))
+
+	for _, name := range []string{"CRC-16/MODBUS", "CRC-32/BZIP2", "CRC-12/UMTS", "CRC-64/XZ", "CRC-5/USB", "CRC-24/BLE"} {
+		m, ok := lookupCRCModel(name)
+		assert.Equal(t, true, ok, name)
+		f := newTestCRCFinder(m, 4, 10, 17)
+		f.Width(m.Width)
+		res, err := f.Find()
+		assert.Equal(t, nil, err, name)
+		assert.Equal(t, 1, len(res), name)
+		if len(res) > 0 {
+			assert.Equal(t, name, res[0].Name)
+		}
+	}
+
+	f := newTestCRCFinder(custom, 4, 20, 33)
+	f.Width(13)
+	res, err := f.Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []CRCModel{custom}, res)
+	assert.Equal(t, "width=13  poly=0x1d0f  init=0x0123  refin=true  refout=true  xorout=0x1abc  check=0x04bd  name=(none)", res[0].String())
+}
+
+func TestCRCFinderWidths(t *testing.T) {
+	// without width, all widths fitting the 2 byte checksums are searched
+	m, _ := lookupCRCModel("CRC-16/XMODEM")
+	res, err := newTestCRCFinder(m, 6, 12, 40).Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, len(res) >= 1)
+	assert.Equal(t, "CRC-16/XMODEM", res[0].Name)
+}
+
+func TestCRCFinderSameLength(t *testing.T) {
+	// with all messages of the same length, the initial value and final xor can't be told apart
+	m, _ := lookupCRCModel("CRC-32/ISO-HDLC")
+	f := newTestCRCFinder(m, 5, 32)
+	f.Width(32)
+	res, err := f.Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, len(res) > 1)
+	assert.Equal(t, "CRC-32/ISO-HDLC", res[0].Name)
+}
+
+func TestCRCFinderErrors(t *testing.T) {
+	f := NewCRCFinder()
+	_, err := f.Find()
+	assert.NotEqual(t, nil, err)
+
+	assert.NotEqual(t, nil, f.AddSample([]byte("a"), []byte{}))
+	assert.Equal(t, nil, f.AddSample([]byte("a"), []byte{1, 2}))
+	assert.NotEqual(t, nil, f.AddSample([]byte("b"), []byte{1}))
+
+	// no known model, and no messages of the same length
+	f = NewCRCFinder()
+	_ = f.AddSample([]byte("a"), []byte{0x12, 0x34})
+	_ = f.AddSample([]byte("bc"), []byte{0x56, 0x78})
+	_, err = f.Find()
+	assert.NotEqual(t, nil, err)
+}
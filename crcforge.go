@@ -0,0 +1,98 @@
+package gohash
+
+import (
+	"fmt"
+	"io"
+)
+
+// ForgeCRC returns the bytes to write at `offset` of `data`, replacing the bytes
+// there, for the CRC `algo` of the data to become `target`. With an offset of
+// len(data) the bytes are to be appended. As a CRC is linear, the bytes are
+// solved for directly instead of searched for
+func ForgeCRC(algo string, data []byte, offset int, target []byte) ([]byte, error) {
+
+	h, err := NewHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	crc, ok := h.(*crcHash)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a CRC", algo)
+	}
+	m := crc.model
+	size := m.size()
+	if len(target) != size {
+		return nil, fmt.Errorf("target must be %d bytes, is %d", size, len(target))
+	}
+	if offset < 0 || offset > len(data) || (offset != len(data) && offset+size > len(data)) {
+		return nil, fmt.Errorf("offset %d out of range", offset)
+	}
+
+	var want uint64
+	for _, b := range target {
+		want = want<<8 | uint64(b)
+	}
+	if want&m.mask() != want {
+		return nil, fmt.Errorf("target does not fit width %d", m.Width)
+	}
+
+	// the CRC of the data with zeroed patch bytes
+	_, _ = h.Write(data[:offset])
+	_, _ = h.Write(make([]byte, size))
+	if offset < len(data) {
+		_, _ = h.Write(data[offset+size:])
+	}
+	rhs := want ^ crc.Sum64()
+
+	// each patch bit flips the CRC by the CRC of the bit followed by the rest of the data,
+	// calculated without initial value and final xor
+	suffix := 0
+	if offset < len(data) {
+		suffix = len(data) - offset - size
+	}
+	linear := m
+	linear.Init, linear.XorOut = 0, 0
+	cols := make([]uint64, size*8)
+	patch := make([]byte, size)
+	for j := range cols {
+		patch[j/8] = 0x80 >> uint(j%8)
+		h, _ := NewCRC(linear)
+		_, _ = h.Write(patch)
+		writeZeros(h, suffix)
+		cols[j] = h.(*crcHash).Sum64()
+		patch[j/8] = 0
+	}
+
+	rows := make([]gf2Row, m.Width)
+	for i := range rows {
+		rows[i].rhs = rhs>>uint(i)&1 == 1
+		for j, col := range cols {
+			if col>>uint(i)&1 == 1 {
+				rows[i].a |= 1 << uint(j)
+			}
+		}
+	}
+	bits, _, _, ok := gf2Solve(rows, size*8, 0)
+	if !ok {
+		return nil, fmt.Errorf("no bytes at offset %d give the target crc", offset)
+	}
+	for j := 0; j < size*8; j++ {
+		if bits>>uint(j)&1 == 1 {
+			patch[j/8] |= 0x80 >> uint(j%8)
+		}
+	}
+	return patch, nil
+}
+
+// writes `n` zero bytes to `w`
+func writeZeros(w io.Writer, n int) {
+	zeros := make([]byte, 64*1024)
+	for n > 0 {
+		c := len(zeros)
+		if c > n {
+			c = n
+		}
+		_, _ = w.Write(zeros[:c])
+		n -= c
+	}
+}
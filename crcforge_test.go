@@ -0,0 +1,60 @@
+package gohash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForgeCRC(t *testing.T) {
+	data := []byte(fox)
+	tests := []struct {
+		algo   string
+		offset int
+		target string
+	}{
+		{"crc32", len(data), "deadbeef"},
+		{"crc32", 0, "00000000"},
+		{"crc32", 10, "12345678"},
+		{"crc-32/bzip2", 39, "cafebabe"},
+		{"crc-16/modbus", len(data), "1234"},
+		{"crc16-ccitt-false", 5, "abcd"},
+		{"crc-64/xz", len(data), "0123456789abcdef"},
+		{"crc-64/we", 20, "fedcba9876543210"},
+		{"crc-12/umts", 3, "0abc"},
+		{"crc-5/usb", len(data), "1f"},
+		{"crc-16/arc:init=ffff", len(data), "beef"},
+	}
+	for _, test := range tests {
+		target, _ := hex.DecodeString(test.target)
+		patch, err := ForgeCRC(test.algo, data, test.offset, target)
+		assert.Equal(t, nil, err, test.algo)
+
+		forged := append([]byte{}, data[:test.offset]...)
+		forged = append(forged, patch...)
+		if test.offset < len(data) {
+			forged = append(forged, data[test.offset+len(patch):]...)
+		}
+		res, _ := NewCalculator(bytes.NewReader(forged)).Sum(test.algo)
+		assert.Equal(t, test.target, hex.EncodeToString(res), test.algo)
+	}
+
+	invalid := []struct {
+		algo   string
+		offset int
+		target string
+	}{
+		{"sha1", 0, "00"},
+		{"crc32", 0, "0000"},
+		{"crc32", -1, "00000000"},
+		{"crc32", len(data) - 2, "00000000"},
+		{"crc-12/umts", 0, "ffff"},
+	}
+	for _, test := range invalid {
+		target, _ := hex.DecodeString(test.target)
+		_, err := ForgeCRC(test.algo, data, test.offset, target)
+		assert.NotEqual(t, nil, err, test.algo)
+	}
+}
@@ -0,0 +1,422 @@
+package gohash
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// crypt(3) password hashes, in the modular crypt format "$id$salt$hash".
+// The digest of md5crypt, apr1, sha256crypt and sha512crypt is the raw hash,
+// before the byte shuffling and base64 encoding of the crypt string
+
+const (
+	cryptAlphabet  = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	bcryptAlphabet = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+	md5CryptMaxSalt = 8
+	shaCryptMaxSalt = 16
+
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+)
+
+var bcryptEncoding = base64.NewEncoding(bcryptAlphabet).WithPadding(base64.NoPadding)
+
+// the order of the digest bytes in crypt strings, in groups of three bytes with
+// the most significant first. -1 is a zero byte
+var (
+	md5CryptOrder = [][3]int{
+		{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}, {-1, -1, 11},
+	}
+	sha256CryptOrder = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29}, {-1, 31, 30},
+	}
+	sha512CryptOrder = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41}, {-1, -1, 63},
+	}
+)
+
+// decodes the crypt base64 encoding of a digest of `size` bytes, ordered by `order`
+func decodeCryptBase64(s string, order [][3]int, size int) ([]byte, error) {
+	res := make([]byte, size)
+	for _, group := range order {
+		bytes := 0
+		for _, i := range group {
+			if i != -1 {
+				bytes++
+			}
+		}
+		chars := (bytes*8 + 5) / 6
+		if len(s) < chars {
+			return nil, fmt.Errorf("hash too short")
+		}
+		w := 0
+		for i := chars - 1; i >= 0; i-- {
+			v := strings.IndexByte(cryptAlphabet, s[i])
+			if v == -1 {
+				return nil, fmt.Errorf("invalid character '%c' in hash", s[i])
+			}
+			w = w<<6 | v
+		}
+		s = s[chars:]
+		for j, i := range group {
+			if i != -1 {
+				res[i] = byte(w >> uint(16-8*j))
+			}
+		}
+	}
+	if s != "" {
+		return nil, fmt.Errorf("hash too long")
+	}
+	return res, nil
+}
+
+// md5Crypt is the md5crypt of FreeBSD, and apr1 of Apache with magic "$apr1$"
+func md5Crypt(password, salt []byte, magic string) []byte {
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write(salt)
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	h := md5.New()
+	h.Write(password)
+	h.Write([]byte(magic))
+	h.Write(salt)
+	for n := len(password); n > 0; n -= md5.Size {
+		if n > md5.Size {
+			h.Write(altSum)
+		} else {
+			h.Write(altSum[:n])
+		}
+	}
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 == 1 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+	sum := h.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		h.Reset()
+		if i&1 == 1 {
+			h.Write(password)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write(salt)
+		}
+		if i%7 != 0 {
+			h.Write(password)
+		}
+		if i&1 == 1 {
+			h.Write(sum)
+		} else {
+			h.Write(password)
+		}
+		sum = h.Sum(sum[:0])
+	}
+	return sum
+}
+
+// shaCrypt is sha256crypt and sha512crypt, see https://www.akkadia.org/drepper/SHA-crypt.txt
+func shaCrypt(newHash func() hash.Hash, password, salt []byte, rounds int) []byte {
+	size := newHash().Size()
+
+	// repeats `sum` to length `n`
+	repeat := func(sum []byte, n int) []byte {
+		res := make([]byte, 0, n)
+		for len(res) < n {
+			c := n - len(res)
+			if c > len(sum) {
+				c = len(sum)
+			}
+			res = append(res, sum[:c]...)
+		}
+		return res
+	}
+
+	b := newHash()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	bSum := b.Sum(nil)
+
+	a := newHash()
+	a.Write(password)
+	a.Write(salt)
+	a.Write(repeat(bSum, len(password)))
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 == 1 {
+			a.Write(bSum)
+		} else {
+			a.Write(password)
+		}
+	}
+	aSum := a.Sum(nil)
+
+	dp := newHash()
+	for i := 0; i < len(password); i++ {
+		dp.Write(password)
+	}
+	p := repeat(dp.Sum(nil), len(password))
+
+	ds := newHash()
+	for i := 0; i < 16+int(aSum[0]); i++ {
+		ds.Write(salt)
+	}
+	s := repeat(ds.Sum(nil), len(salt))
+
+	c := aSum
+	h := newHash()
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 == 1 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 == 1 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(c[:0])
+	}
+	return c[:size]
+}
+
+func newMD5Crypt(magic string) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newMD5CryptWithParams(magic)(HashParams{})
+		return h
+	}
+}
+
+// md5crypt and apr1 take a salt of up to 8 bytes
+func newMD5CryptWithParams(magic string) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("salt"); err != nil {
+			return nil, err
+		}
+		salt, err := cryptSalt(p, md5CryptMaxSalt)
+		if err != nil {
+			return nil, err
+		}
+		return newBufferedHash(md5.Size, func(password []byte) []byte {
+			return md5Crypt(password, salt, magic)
+		}), nil
+	}
+}
+
+func newSHACrypt(newHash func() hash.Hash) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newSHACryptWithParams(newHash)(HashParams{})
+		return h
+	}
+}
+
+// sha256crypt and sha512crypt take a number of rounds and a salt of up to 16 bytes
+func newSHACryptWithParams(newHash func() hash.Hash) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("rounds", "salt"); err != nil {
+			return nil, err
+		}
+		rounds, err := p.int("rounds", shaCryptDefaultRounds)
+		if err != nil {
+			return nil, err
+		}
+		if rounds < shaCryptMinRounds || rounds > shaCryptMaxRounds {
+			return nil, fmt.Errorf("rounds must be %d to %d, is %d", shaCryptMinRounds, shaCryptMaxRounds, rounds)
+		}
+		salt, err := cryptSalt(p, shaCryptMaxSalt)
+		if err != nil {
+			return nil, err
+		}
+		return newBufferedHash(newHash().Size(), func(password []byte) []byte {
+			return shaCrypt(newHash, password, salt, rounds)
+		}), nil
+	}
+}
+
+// returns the salt parameter, which can't contain "$" in a crypt string
+func cryptSalt(p HashParams, max int) ([]byte, error) {
+	salt, err := p.bytes("salt")
+	if err != nil {
+		return nil, err
+	}
+	if len(salt) > max {
+		return nil, fmt.Errorf("salt must be at most %d bytes, is %d", max, len(salt))
+	}
+	if strings.ContainsAny(string(salt), "$:\n") {
+		return nil, fmt.Errorf("invalid salt")
+	}
+	return salt, nil
+}
+
+var cryptHashes = []HashAlgorithm{
+	{Name: "apr1", Bits: 128, Family: "password", New: newMD5Crypt("$apr1$"), NewWithParams: newMD5CryptWithParams("$apr1$")},
+	{Name: "md5crypt", Bits: 128, Family: "password", New: newMD5Crypt("$1$"), NewWithParams: newMD5CryptWithParams("$1$")},
+	{Name: "sha256crypt", Bits: 256, Family: "password", New: newSHACrypt(sha256.New), NewWithParams: newSHACryptWithParams(sha256.New)},
+	{Name: "sha512crypt", Bits: 512, Family: "password", New: newSHACrypt(sha512.New), NewWithParams: newSHACryptWithParams(sha512.New)},
+}
+
+func init() {
+	for _, algo := range cryptHashes {
+		mustRegisterHash(algo)
+	}
+}
+
+// ParseCrypt parses a crypt(3) string such as "$6$rounds=5000$salt$hash", returning
+// the algorithm id with parameters and the raw hash. md5crypt ($1$), apr1 ($apr1$),
+// sha256crypt ($5$), sha512crypt ($6$), bcrypt ($2a$, $2b$, $2y$) and argon2
+// ($argon2i$, $argon2id$) are supported
+func ParseCrypt(s string) (string, []byte, error) {
+	parts := strings.Split(s, "$")
+	if len(parts) < 3 || parts[0] != "" {
+		return "", nil, fmt.Errorf("not a crypt string")
+	}
+	fields := parts[2:]
+	switch parts[1] {
+	case "1":
+		return parseMD5Crypt("md5crypt", fields)
+	case "apr1":
+		return parseMD5Crypt("apr1", fields)
+	case "5":
+		return parseSHACrypt("sha256crypt", sha256.Size, sha256CryptOrder, fields)
+	case "6":
+		return parseSHACrypt("sha512crypt", sha512.Size, sha512CryptOrder, fields)
+	case "2a", "2b", "2y":
+		return parseBcrypt(fields)
+	case "argon2i", "argon2id":
+		return parseArgon2(parts[1], fields)
+	}
+	return "", nil, fmt.Errorf("unsupported crypt id $%s$", parts[1])
+}
+
+// VerifyCrypt returns true if `password` matches the crypt(3) string `s`
+func VerifyCrypt(s string, password []byte) (bool, error) {
+	algo, expected, err := ParseCrypt(s)
+	if err != nil {
+		return false, err
+	}
+	return NewCalculator(strings.NewReader(string(password))).Verify(algo, expected)
+}
+
+func parseMD5Crypt(name string, fields []string) (string, []byte, error) {
+	if len(fields) != 2 || len(fields[0]) > md5CryptMaxSalt {
+		return "", nil, fmt.Errorf("malformed %s string", name)
+	}
+	sum, err := decodeCryptBase64(fields[1], md5CryptOrder, md5.Size)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return name + ":salt=" + hex.EncodeToString([]byte(fields[0])), sum, nil
+}
+
+func parseSHACrypt(name string, size int, order [][3]int, fields []string) (string, []byte, error) {
+	params := HashParams{}
+	if len(fields) == 3 && strings.HasPrefix(fields[0], "rounds=") {
+		rounds, err := strconv.Atoi(fields[0][len("rounds="):])
+		if err != nil {
+			return "", nil, fmt.Errorf("malformed %s string", name)
+		}
+		// out of range rounds are clamped, as by glibc
+		if rounds < shaCryptMinRounds {
+			rounds = shaCryptMinRounds
+		}
+		if rounds > shaCryptMaxRounds {
+			rounds = shaCryptMaxRounds
+		}
+		params["rounds"] = strconv.Itoa(rounds)
+		fields = fields[1:]
+	}
+	if len(fields) != 2 {
+		return "", nil, fmt.Errorf("malformed %s string", name)
+	}
+	// longer salts are truncated, as by glibc
+	salt := fields[0]
+	if len(salt) > shaCryptMaxSalt {
+		salt = salt[:shaCryptMaxSalt]
+	}
+	params["salt"] = hex.EncodeToString([]byte(salt))
+	sum, err := decodeCryptBase64(fields[1], order, size)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return name + ":" + params.String(), sum, nil
+}
+
+func parseBcrypt(fields []string) (string, []byte, error) {
+	if len(fields) != 2 || len(fields[0]) != 2 || len(fields[1]) != 22+31 {
+		return "", nil, fmt.Errorf("malformed bcrypt string")
+	}
+	cost, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed bcrypt string")
+	}
+	salt, err := bcryptEncoding.DecodeString(fields[1][:22])
+	if err != nil {
+		return "", nil, fmt.Errorf("bcrypt: invalid salt")
+	}
+	sum, err := bcryptEncoding.DecodeString(fields[1][22:])
+	if err != nil {
+		return "", nil, fmt.Errorf("bcrypt: invalid hash")
+	}
+	return fmt.Sprintf("bcrypt:cost=%d,salt=%x", cost, salt), sum, nil
+}
+
+// parses the PHC string format of argon2, "$argon2id$v=19$m=65536,t=2,p=1$salt$hash"
+// with the salt and hash in base64 without padding
+func parseArgon2(name string, fields []string) (string, []byte, error) {
+	if len(fields) == 4 {
+		if fields[0] != "v=19" {
+			return "", nil, fmt.Errorf("%s: unsupported version %s", name, fields[0])
+		}
+		fields = fields[1:]
+	}
+	if len(fields) != 3 {
+		return "", nil, fmt.Errorf("malformed %s string", name)
+	}
+	params := HashParams{}
+	for _, kv := range strings.Split(fields[0], ",") {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 || !isStringInSlice(pair[0], []string{"m", "t", "p"}) {
+			return "", nil, fmt.Errorf("%s: unsupported parameter %s", name, kv)
+		}
+		params[pair[0]] = pair[1]
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: invalid salt", name)
+	}
+	sum, err := base64.RawStdEncoding.DecodeString(fields[2])
+	if err != nil || len(sum) == 0 {
+		return "", nil, fmt.Errorf("%s: invalid hash", name)
+	}
+	params["salt"] = hex.EncodeToString(salt)
+	params["output"] = strconv.Itoa(len(sum))
+	return name + ":" + params.String(), sum, nil
+}
@@ -0,0 +1,73 @@
+package gohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCrypt(t *testing.T) {
+	for _, tc := range []struct {
+		crypt    string
+		password string
+	}{
+		// from openssl passwd
+		{"$1$saltsalt$le8lFSqqnPaRFOlmAZpvH1", "Hello world!"},
+		{"$1$$qRPK7m23GJusamGpoGLby/", ""},
+		{"$apr1$saltsalt$6BwcdpRros16.J9J/tHRr/", "Hello world!"},
+		// from the SHA-crypt specification
+		{"$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", "Hello world!"},
+		{"$5$rounds=10000$saltstringsaltstring$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", "Hello world!"},
+		{"$5$rounds=10$roundstoolow$yfvwcWrQ8l/K0DAWyuPMDNHpIVlTQebY9l/gL972bIC", "the minimum number is still observed"},
+		{"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", "Hello world!"},
+		{"$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.", "Hello world!"},
+		// from the OpenBSD bcrypt tests
+		{"$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", "U*U"},
+		{"$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW", "U*U"},
+		// from the argon2 reference implementation
+		{"$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", "password"},
+		{"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc", "password"},
+	} {
+		ok, err := VerifyCrypt(tc.crypt, []byte(tc.password))
+		assert.Equal(t, nil, err, tc.crypt)
+		assert.Equal(t, true, ok, tc.crypt)
+
+		ok, err = VerifyCrypt(tc.crypt, []byte(tc.password+"!"))
+		assert.Equal(t, nil, err, tc.crypt)
+		assert.Equal(t, false, ok, tc.crypt)
+	}
+}
+
+func TestParseCrypt(t *testing.T) {
+	algo, _, err := ParseCrypt("$6$rounds=10000$saltstringsaltstring$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha512crypt:rounds=10000,salt=73616c74737472696e6773616c747374", algo)
+
+	algo, _, err = ParseCrypt("$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "bcrypt:cost=5,salt=10410410410410410410410410410410", algo)
+
+	algo, _, err = ParseCrypt("$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "argon2id:m=65536,output=32,p=1,salt=736f6d6573616c74,t=2", algo)
+
+	for _, s := range []string{
+		"",
+		"5f4dcc3b5aa765d61d8327deb882cf99",
+		"$7$abc",
+		"$1$saltsalt",
+		"$1$saltsaltsalt$le8lFSqqnPaRFOlmAZpvH1",
+		"$1$saltsalt$le8lFSqqnPaRFOlmAZpvH",
+		"$1$saltsalt$le8lFSqqnPaRFOlmAZpvH1x",
+		"$1$saltsalt$le8lFSqqnPaRFOlmAZpvH!",
+		"$5$rounds=x$salt$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+		"$2b$5$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+		"$2b$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOe",
+		"$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=2,p=1,keyid=abc$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$",
+	} {
+		_, _, err := ParseCrypt(s)
+		assert.NotEqual(t, nil, err, s)
+	}
+}
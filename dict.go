@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -16,7 +17,9 @@ type Dictionary struct {
 	dictFileName  string
 	lines         []string
 	expected      []byte
+	expectedErr   error
 	possibleAlgos []string
+	useAlgo       string
 	prefix        string
 	suffix        string
 
@@ -46,14 +49,22 @@ func NewDictionary(dictFileName string) (*Dictionary, error) {
 	}, nil
 }
 
+// Algo sets the hash algorithm ("bcrypt:cost=5,salt=00ff..."), instead of trying all
+// algorithms of the expected size. Password hashes are only tried if set
+func (d *Dictionary) Algo(algo string) { d.useAlgo = algo }
+
 // Prefix sets a fixed prefix
 func (d *Dictionary) Prefix(s string) { d.prefix = s }
 
 // Suffix sets a fixed suffix
 func (d *Dictionary) Suffix(s string) { d.suffix = s }
 
-// ExpectedHash sets the expected hash
+// ExpectedHash sets the expected hash, in hex or as a crypt(3) string which also sets the algorithm
 func (d *Dictionary) ExpectedHash(expected string) {
+	if strings.HasPrefix(expected, "$") {
+		d.useAlgo, d.expected, d.expectedErr = ParseCrypt(expected)
+		return
+	}
 	tmp, _ := decodeHex(strings.NewReader(expected))
 	d.expected = tmp[:]
 }
@@ -120,11 +131,44 @@ func (d *Dictionary) equals(algo string, r io.Reader) bool {
 // derive possible hashes from bitsize
 func (d *Dictionary) decidePossibleAlgos() error {
 
+	if d.expectedErr != nil {
+		return d.expectedErr
+	}
+
 	bitSize := len(d.expected) * 8
 
-	for algo, algoBitSize := range algos {
-		if algoBitSize == bitSize {
-			d.possibleAlgos = append(d.possibleAlgos, algo)
+	if d.useAlgo != "" {
+		id, err := withOutputSize(d.useAlgo, len(d.expected))
+		if err != nil {
+			return err
+		}
+		h, err := NewHash(id)
+		if err != nil {
+			return err
+		}
+		if h.Size()*8 != bitSize {
+			return fmt.Errorf("expected hash is wrong size, should be %d bit, is %d", h.Size()*8, bitSize)
+		}
+		d.possibleAlgos = []string{id}
+		return nil
+	}
+
+	for _, algo := range registeredHashes() {
+		// password hashes are slow and need their salt, so they are only tried if set with Algo.
+		// p2p hashes of short input are the same as md4, sha1 and tiger192
+		if algo.Family == "password" || algo.Family == "p2p" {
+			continue
+		}
+		if algo.Bits == bitSize {
+			d.possibleAlgos = append(d.possibleAlgos, algo.Name)
+			continue
+		}
+		if algo.VariableSize {
+			// skip sizes not supported by the algorithm
+			id := algo.Name + ":output=" + strconv.Itoa(len(d.expected))
+			if _, err := NewHash(id); err == nil {
+				d.possibleAlgos = append(d.possibleAlgos, id)
+			}
 		}
 	}
 
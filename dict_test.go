@@ -19,3 +19,71 @@ func TestDictionary(t *testing.T) {
 	assert.Equal(t, "sha256", algo)
 	assert.Equal(t, "3qr42dbkhrjp55kg.onion", string(res))
 }
+
+func TestDictionaryVariableSize(t *testing.T) {
+
+	dict, err := NewDictionary("data/onion-sites.txt")
+	assert.Equal(t, nil, err)
+
+	// 40 bit shake128 of a row in onion-sites.txt
+	dict.ExpectedHash("f9b3701ef0")
+
+	res, algo, err := dict.Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "shake128-256:output=5", algo)
+	assert.Equal(t, "3qr42dbkhrjp55kg.onion", string(res))
+}
+
+func TestDictionaryAlgo(t *testing.T) {
+
+	dict, err := NewDictionary("data/onion-sites.txt")
+	assert.Equal(t, nil, err)
+
+	// 128 bit pbkdf2-sha256 of a row in onion-sites.txt, password hashes are only tried if set
+	dict.Algo("pbkdf2-sha256:rounds=10,salt=73616c74")
+	dict.ExpectedHash("0c95409bf0f1258e99a4a9f0c8d1e0e4")
+
+	res, algo, err := dict.Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "pbkdf2-sha256:output=16,rounds=10,salt=73616c74", algo)
+	assert.Equal(t, "3qr42dbkhrjp55kg.onion", string(res))
+
+	dict, _ = NewDictionary("data/onion-sites.txt")
+	dict.Algo("bcrypt")
+	dict.ExpectedHash("0c95409bf0f1258e99a4a9f0c8d1e0e4")
+	_, _, err = dict.Find()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDictionaryCrypt(t *testing.T) {
+
+	dict, err := NewDictionary("data/onion-sites.txt")
+	assert.Equal(t, nil, err)
+
+	// the crypt string gives the algorithm
+	dict.ExpectedHash("$1$onion$J11xbeEaH9p7zqQ1H1LRR.")
+
+	res, algo, err := dict.Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "md5crypt:salt=6f6e696f6e", algo)
+	assert.Equal(t, "3qr42dbkhrjp55kg.onion", string(res))
+
+	dict, _ = NewDictionary("data/onion-sites.txt")
+	dict.ExpectedHash("$1$onion$J11xbeEaH9p7zqQ1H1LRR")
+	_, _, err = dict.Find()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDictionaryLegacy(t *testing.T) {
+
+	dict, err := NewDictionary("data/onion-sites.txt")
+	assert.Equal(t, nil, err)
+
+	// ntlm of a row in onion-sites.txt, legacy password hashes are tried without Algo
+	dict.ExpectedHash("32fb0df4aa35dfdb664146ceaecebb48")
+
+	res, algo, err := dict.Find()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ntlm", algo)
+	assert.Equal(t, "3qr42dbkhrjp55kg.onion", string(res))
+}
@@ -0,0 +1,134 @@
+package gohash
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/ebfe/keccak"
+)
+
+// Ethereum addresses are the last 20 bytes of the keccak256 hash of the secp256k1 public key,
+// shown with the EIP-55 mixed case checksum. See https://eips.ethereum.org/EIPS/eip-55
+
+const ethereumAddressSize = 20
+
+var (
+	// the field prime of secp256k1, y² = x³ + 7
+	secp256k1P = func() *big.Int {
+		p, _ := new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+		return p
+	}()
+	secp256k1B = big.NewInt(7)
+)
+
+// returns x³ + 7 mod p
+func secp256k1Y2(x *big.Int) *big.Int {
+	y2 := new(big.Int).Exp(x, big.NewInt(3), secp256k1P)
+	y2.Add(y2, secp256k1B)
+	return y2.Mod(y2, secp256k1P)
+}
+
+// returns the 64 byte uncompressed form of a 33 byte compressed, 64 byte raw or
+// 65 byte uncompressed secp256k1 public key
+func secp256k1PublicKey(pubKey []byte) ([]byte, error) {
+	switch {
+	case len(pubKey) == 65 && pubKey[0] == 0x04:
+		pubKey = pubKey[1:]
+	case len(pubKey) == 33 && (pubKey[0] == 0x02 || pubKey[0] == 0x03):
+		x := new(big.Int).SetBytes(pubKey[1:])
+		if x.Cmp(secp256k1P) >= 0 {
+			return nil, fmt.Errorf("public key is not on the secp256k1 curve")
+		}
+		// p = 3 mod 4, so the square root is y2^((p+1)/4)
+		exp := new(big.Int).Add(secp256k1P, big.NewInt(1))
+		exp.Rsh(exp, 2)
+		y := new(big.Int).Exp(secp256k1Y2(x), exp, secp256k1P)
+		if y.Bit(0) != uint(pubKey[0]&1) {
+			y.Sub(secp256k1P, y)
+		}
+		res := make([]byte, 64)
+		x.FillBytes(res[:32])
+		y.FillBytes(res[32:])
+		pubKey = res
+	case len(pubKey) != 64:
+		return nil, fmt.Errorf("public key must be 33, 64 or 65 bytes, is %d", len(pubKey))
+	}
+
+	x := new(big.Int).SetBytes(pubKey[:32])
+	y := new(big.Int).SetBytes(pubKey[32:])
+	if x.Cmp(secp256k1P) >= 0 || y.Cmp(secp256k1P) >= 0 ||
+		new(big.Int).Exp(y, big.NewInt(2), secp256k1P).Cmp(secp256k1Y2(x)) != 0 {
+		return nil, fmt.Errorf("public key is not on the secp256k1 curve")
+	}
+	return pubKey, nil
+}
+
+// returns the last 20 bytes of the keccak256 hash of `data`
+func ethereumAddressOf(data []byte) []byte {
+	h := keccak.New256()
+	_, _ = h.Write(data)
+	return h.Sum(nil)[32-ethereumAddressSize:]
+}
+
+// EthereumAddress returns the EIP-55 checksummed address of a secp256k1 public key,
+// which is 33 bytes compressed, 65 bytes uncompressed or 64 bytes without prefix
+func EthereumAddress(pubKey []byte) (string, error) {
+	pubKey, err := secp256k1PublicKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	res, err := encodeEIP55(bytes.NewReader(ethereumAddressOf(pubKey)))
+	return string(res), err
+}
+
+// encodes as 0x prefixed hex, with the letters of the first 64 digits upper case
+// where the matching digit of the keccak256 hash of the lower case hex is 8 or more
+func encodeEIP55(r io.Reader) ([]byte, error) {
+	enc, err := encodeHex(r)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("0x"), eip55Checksum(enc)...), nil
+}
+
+// applies the EIP-55 letter case to lower case hex `enc`
+func eip55Checksum(enc []byte) []byte {
+	h := keccak.New256()
+	_, _ = h.Write(enc)
+	sum := h.Sum(nil)
+
+	res := make([]byte, len(enc))
+	for i, c := range enc {
+		res[i] = c
+		if i >= len(sum)*2 || c < 'a' {
+			continue
+		}
+		nibble := sum[i/2] >> 4
+		if i%2 == 1 {
+			nibble = sum[i/2] & 0xf
+		}
+		if nibble >= 8 {
+			res[i] = c - asciiCaseOffset
+		}
+	}
+	return res
+}
+
+// decodes 0x prefixed hex, verifying the EIP-55 checksum if the letters are of mixed case
+func decodeEIP55(r io.Reader) ([]byte, error) {
+	src, _ := ioutil.ReadAll(r)
+
+	s := stripSeparators(string(src))
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	lower := strings.ToLower(s)
+	if s != lower && s != strings.ToUpper(s) && s != string(eip55Checksum([]byte(lower))) {
+		return nil, fmt.Errorf("eip55 checksum mismatch")
+	}
+	return decodeHex(strings.NewReader(lower))
+}
@@ -0,0 +1,56 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the public key of private key 1, which is the secp256k1 generator point
+const secp256k1G = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+
+func TestEthereumAddress(t *testing.T) {
+	g, _ := hex.DecodeString(secp256k1G)
+	compressed, _ := hex.DecodeString("02" + secp256k1G[2:66])
+
+	for _, pubKey := range [][]byte{g, g[1:], compressed} {
+		addr, err := EthereumAddress(pubKey)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", addr)
+	}
+
+	g[64] ^= 1
+	for _, pubKey := range [][]byte{g, g[:40], compressed[1:], []byte(fox), nil} {
+		_, err := EthereumAddress(pubKey)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestEIP55(t *testing.T) {
+	// from EIP-55
+	for _, addr := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		dec, err := decodeEIP55(strings.NewReader(addr))
+		assert.Equal(t, nil, err, addr)
+		enc, err := encodeEIP55(strings.NewReader(string(dec)))
+		assert.Equal(t, nil, err, addr)
+		assert.Equal(t, addr, string(enc))
+	}
+
+	for _, addr := range []string{
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"0X5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+	} {
+		_, err := decodeEIP55(strings.NewReader(addr))
+		assert.Equal(t, nil, err, addr)
+	}
+
+	_, err := decodeEIP55(strings.NewReader("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"))
+	assert.NotEqual(t, nil, err)
+}
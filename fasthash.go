@@ -0,0 +1,325 @@
+package gohash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/bits"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/dgryski/go-farm"
+	"github.com/go-faster/city"
+	"github.com/minio/highwayhash"
+	"github.com/spaolacci/murmur3"
+	"github.com/zeebo/wyhash"
+	"github.com/zeebo/xxh3"
+)
+
+// fast non-cryptographic hashes. Seeds are given in decimal, or in hex with a "0x" prefix.
+// 64 and 128 bit results are shown big endian, as their integer value, except for
+// highwayhash-128 and highwayhash-256 which are shown as little endian words
+
+// returns the "seed" parameter, which is the only allowed parameter
+func seedParam(p HashParams, bitSize int) (uint64, error) {
+	if err := p.allow("seed"); err != nil {
+		return 0, err
+	}
+	return p.uint("seed", 0, bitSize)
+}
+
+func newXXH32() hash.Hash { return xxhash.New32() }
+
+// xxh32 takes a 32 bit seed
+func newXXH32WithParams(p HashParams) (hash.Hash, error) {
+	seed, err := seedParam(p, 32)
+	if err != nil {
+		return nil, err
+	}
+	return xxhash.NewS32(uint32(seed)), nil
+}
+
+// xxh64 takes a 64 bit seed
+func newXXH64WithParams(p HashParams) (hash.Hash, error) {
+	seed, err := seedParam(p, 64)
+	if err != nil {
+		return nil, err
+	}
+	return xxhash.NewS64(seed), nil
+}
+
+// xxh3Hash128 is XXH3 with 128 bit output
+type xxh3Hash128 struct {
+	*xxh3.Hasher
+}
+
+func (h xxh3Hash128) Size() int { return 16 }
+
+func (h xxh3Hash128) Sum(b []byte) []byte {
+	sum := h.Sum128().Bytes()
+	return append(b, sum[:]...)
+}
+
+func newXXH3(size int, seed uint64) hash.Hash {
+	if size == 16 {
+		return xxh3Hash128{xxh3.NewSeed(seed)}
+	}
+	return xxh3.NewSeed(seed)
+}
+
+// xxh3 takes a 64 bit seed
+func newXXH3WithParams(size int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		seed, err := seedParam(p, 64)
+		if err != nil {
+			return nil, err
+		}
+		return newXXH3(size, seed), nil
+	}
+}
+
+func newMurmur3(size int) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newMurmur3WithParams(size)(HashParams{})
+		return h
+	}
+}
+
+// murmur3 takes a 32 bit seed
+func newMurmur3WithParams(size int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		seed, err := seedParam(p, 32)
+		if err != nil {
+			return nil, err
+		}
+		if size == 16 {
+			return murmur3.New128WithSeed(uint32(seed)), nil
+		}
+		return murmur3.New32WithSeed(uint32(seed)), nil
+	}
+}
+
+// the key of highwayhash without a key parameter
+var highwayHashEmptyKey = make([]byte, highwayhash.Size)
+
+func newHighwayHash(size int, key []byte) (hash.Hash, error) {
+	if len(key) != highwayhash.Size {
+		return nil, fmt.Errorf("highwayhash key must be %d bytes, is %d", highwayhash.Size, len(key))
+	}
+	switch size {
+	case highwayhash.Size64:
+		h, err := highwayhash.New64(key)
+		return highwayHash64{h}, err
+	case highwayhash.Size128:
+		return highwayhash.New128(key)
+	}
+	return highwayhash.New(key)
+}
+
+// highwayHash64 is HighwayHash-64 shown big endian, as the reference implementation
+type highwayHash64 struct {
+	hash.Hash64
+}
+
+func (h highwayHash64) Sum(b []byte) []byte { return append(b, uint64Bytes(h.Sum64())...) }
+
+func newHighway(size int) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newHighwayHash(size, highwayHashEmptyKey)
+		return h
+	}
+}
+
+// highwayhash takes a 32 byte key
+func newHighwayWithParams(size int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("key"); err != nil {
+			return nil, err
+		}
+		key, err := p.bytes("key")
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			key = highwayHashEmptyKey
+		}
+		return newHighwayHash(size, key)
+	}
+}
+
+// returns `v` as 4 bytes, big endian
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// returns `v` as 8 bytes, big endian
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// returns the 128 bit value of `hi` and `lo` as 16 bytes, big endian
+func uint128Bytes(hi, lo uint64) []byte {
+	return append(uint64Bytes(hi), uint64Bytes(lo)...)
+}
+
+// the one-shot hashes below have no streaming api, so the input is buffered
+
+func newCity32() hash.Hash {
+	return newBufferedHash(4, func(data []byte) []byte { return uint32Bytes(city.Hash32(data)) })
+}
+
+func newCity64(seed uint64) hash.Hash {
+	return newBufferedHash(8, func(data []byte) []byte {
+		if seed != 0 {
+			return uint64Bytes(city.Hash64WithSeed(data, seed))
+		}
+		return uint64Bytes(city.Hash64(data))
+	})
+}
+
+// city64 takes a 64 bit seed, using CityHash64WithSeed if set
+func newCity64WithParams(p HashParams) (hash.Hash, error) {
+	seed, err := seedParam(p, 64)
+	if err != nil {
+		return nil, err
+	}
+	return newCity64(seed), nil
+}
+
+func newCity128() hash.Hash {
+	return newBufferedHash(16, func(data []byte) []byte {
+		sum := city.Hash128(data)
+		return uint128Bytes(sum.High, sum.Low)
+	})
+}
+
+// the farmhash fingerprints, which are the same on all platforms
+
+func newFarm32() hash.Hash {
+	return newBufferedHash(4, func(data []byte) []byte { return uint32Bytes(farm.Fingerprint32(data)) })
+}
+
+func newFarm64() hash.Hash {
+	return newBufferedHash(8, func(data []byte) []byte { return uint64Bytes(farm.Fingerprint64(data)) })
+}
+
+func newFarm128() hash.Hash {
+	return newBufferedHash(16, func(data []byte) []byte {
+		lo, hi := farm.Fingerprint128(data)
+		return uint128Bytes(hi, lo)
+	})
+}
+
+// wyHash is a streaming wyhash v3, as implemented by zeebo/wyhash. Input up to 256 bytes
+// is hashed with wyhash.Hash, longer input in blocks of 256 bytes followed by the last
+// 1 to 256 bytes, so a block is only hashed once more input follows it
+type wyHash struct {
+	seed   uint64
+	s0, s1 uint64
+	x      [0x100]byte
+	nx     int
+	length uint64
+}
+
+const (
+	wyp0 = 0xa0761d6478bd642f
+	wyp1 = 0xe7037ed1a0b428db
+	wyp2 = 0x8ebc6af09c88c6e3
+	wyp3 = 0x589965cc75374cc3
+	wyp4 = 0x1d8e4e27c47d124f
+)
+
+func newWyhash(seed uint64) hash.Hash {
+	d := &wyHash{seed: seed}
+	d.Reset()
+	return d
+}
+
+func (d *wyHash) Reset() {
+	d.s0, d.s1 = d.seed, d.seed
+	d.nx = 0
+	d.length = 0
+}
+
+func (d *wyHash) Size() int      { return 8 }
+func (d *wyHash) BlockSize() int { return 0x100 }
+
+func wymum(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+// returns the little endian word of `p` at `i`
+func wyr8(p []byte, i int) uint64 { return binary.LittleEndian.Uint64(p[i:]) }
+
+// returns the little endian word of `p` at `i` with its halves swapped, as read by
+// wyhash for the last 32 bytes
+func wyr9(p []byte, i int) uint64 {
+	return uint64(binary.LittleEndian.Uint32(p[i:]))<<32 | uint64(binary.LittleEndian.Uint32(p[i+4:]))
+}
+
+func (d *wyHash) block(p []byte) {
+	for i := 0; i < 0x100; i += 0x40 {
+		d.s0 = wymum(wyr8(p, i)^d.s0^wyp0, wyr8(p, i+0x08)^d.s0^wyp1) ^ wymum(wyr8(p, i+0x10)^d.s0^wyp2, wyr8(p, i+0x18)^d.s0^wyp3)
+		d.s1 = wymum(wyr8(p, i+0x20)^d.s1^wyp1, wyr8(p, i+0x28)^d.s1^wyp2) ^ wymum(wyr8(p, i+0x30)^d.s1^wyp3, wyr8(p, i+0x38)^d.s1^wyp0)
+	}
+}
+
+func (d *wyHash) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+	for len(p) > 0 {
+		if d.nx == len(d.x) {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *wyHash) Sum(b []byte) []byte {
+	if d.length <= 0x100 {
+		return append(b, uint64Bytes(wyhash.Hash(d.x[:d.nx], d.seed))...)
+	}
+	s0, s1 := d.s0, d.s1
+	p := d.x[:d.nx]
+	for len(p) > 0x20 {
+		s0 = wymum(wyr8(p, 0x00)^s0^wyp0, wyr8(p, 0x08)^s0^wyp1)
+		s1 = wymum(wyr8(p, 0x10)^s1^wyp2, wyr8(p, 0x18)^s1^wyp3)
+		p = p[0x20:]
+	}
+	off := len(p)
+	switch {
+	case off > 0x18:
+		s0 = wymum(wyr9(p, 0x00)^s0^wyp0, wyr9(p, 0x08)^s0^wyp1)
+		s1 = wymum(wyr9(p, 0x10)^s1^wyp2, wyr9(p, off-0x08)^s1^wyp3)
+	case off > 0x10:
+		s0 = wymum(wyr9(p, 0x00)^s0^wyp0, wyr9(p, 0x08)^s0^wyp1)
+		s1 = wymum(wyr9(p, off-0x08)^s1^wyp2, s1^wyp3)
+	case off > 0x08:
+		s0 = wymum(wyr9(p, 0x00)^s0^wyp0, wyr9(p, off-0x08)^s0^wyp1)
+	case off > 0x03:
+		w0 := uint64(binary.LittleEndian.Uint32(p))
+		w1 := uint64(binary.LittleEndian.Uint32(p[off-0x04:]))
+		s0 = wymum(w0^s0^wyp0, w1^s0^wyp1)
+	default:
+		w := uint64(p[0])<<16 | uint64(p[off>>1])<<8 | uint64(p[off-1])
+		s0 = wymum(w^s0^wyp0, s0^wyp1)
+	}
+	return append(b, uint64Bytes(wymum(s0^s1, d.length^wyp4))...)
+}
+
+// wyhash takes a 64 bit seed
+func newWyhashWithParams(p HashParams) (hash.Hash, error) {
+	seed, err := seedParam(p, 64)
+	if err != nil {
+		return nil, err
+	}
+	return newWyhash(seed), nil
+}
@@ -0,0 +1,105 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zeebo/wyhash"
+)
+
+func TestFastHashesSeeded(t *testing.T) {
+	for _, tc := range []struct {
+		algo     string
+		input    string
+		expected string
+	}{
+		{"xxh32", "abc", "32d153ff"},
+		{"xxh32:seed=1", "", "0b2cb792"},
+		{"xxh64:seed=0", fox, "0b242d361fda71bc"},
+		{"xxh3-64", "abc", "78af5f94892f3950"},
+		{"xxh3-128", "", "99aa06d3014798d86001c324468d497f"},
+		// from the smhasher verification values
+		{"murmur3-32:seed=1", "", "514e28b7"},
+		{"murmur3-32:seed=0xffffffff", "", "81f16f39"},
+		{"murmur3-32:seed=4294967295", "", "81f16f39"},
+		{"city64", "", "9ae16a3b2f90404f"},
+		{"city64:seed=1", fox, "48e61f91afa90d5c"},
+		// farmhash fingerprint128 is cityhash128
+		{"farm128", fox, "bf1498f876dbe279a7f9a86a2d60c968"},
+		// from the highwayhash reference implementation
+		{"highwayhash-64:key=000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "", "907a56de22c26e53"},
+		{"wyhash:seed=1", fox, "f68b9c01381ae884"},
+	} {
+		calc := NewCalculator(strings.NewReader(tc.input))
+		sum, err := calc.Sum(tc.algo)
+		assert.Equal(t, nil, err, tc.algo)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo)
+	}
+
+	for _, algo := range []string{
+		"xxh32:seed=4294967296", "murmur3-32:seed=-1", "xxh3-64:seed=0xz",
+		"wyhash:key=00", "highwayhash-64:key=00", "farm64:seed=1",
+	} {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+}
+
+func TestFastHashesKeyed(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	calc := NewCalculator(strings.NewReader(""))
+	calc.Key(key)
+	sum, err := calc.Sum("highwayhash-64")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "907a56de22c26e53", hex.EncodeToString(sum))
+}
+
+func TestWyhashStreaming(t *testing.T) {
+	// the result must match wyhash.Hash for any length and split of the input
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 1200; n++ {
+		data := make([]byte, n)
+		r.Read(data)
+		seed := r.Uint64()
+		h := newWyhash(seed)
+		for p := data; len(p) > 0; {
+			c := r.Intn(300) + 1
+			if c > len(p) {
+				c = len(p)
+			}
+			_, _ = h.Write(p[:c])
+			p = p[c:]
+		}
+		assert.Equal(t, uint64Bytes(wyhash.Hash(data, seed)), h.Sum(nil), n)
+	}
+}
+
+func TestOneShotHashLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	_, err := NewCalculator(&patternReader{n: bufferedHashLimit + 1}).Sum("city64")
+	assert.NotEqual(t, nil, err)
+
+	_, err = NewCalculator(&patternReader{n: bufferedHashLimit + 1}).SumMany([]string{"sha1", "farm64"})
+	assert.NotEqual(t, nil, err)
+
+	sum, err := NewCalculator(&patternReader{n: bufferedHashLimit}).Sum("city64")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 8, len(sum))
+
+	// a hash.Hash never returns a digest of the truncated input
+	h, _ := NewHash("farm64")
+	n, err := io.Copy(h, &patternReader{n: bufferedHashLimit + 1})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(bufferedHashLimit+1), n)
+	assert.Panics(t, func() { h.Sum(nil) })
+
+	h.Reset()
+	_, _ = h.Write([]byte(fox))
+	assert.Equal(t, expectedHashes["farm64"][fox], hex.EncodeToString(h.Sum(nil)))
+}
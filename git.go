@@ -0,0 +1,176 @@
+package gohash
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// git object ids are the hash of the object with a "<type> <size>\0" header, using sha1
+// or sha256 depending on the object format of the repository
+
+// the tree entry modes of git
+const (
+	gitModeFile       = "100644"
+	gitModeExecutable = "100755"
+	gitModeSymlink    = "120000"
+	gitModeTree       = "40000"
+)
+
+// returns a new hash of git object format `format`, "sha1" or "sha256"
+func newGitHash(format string) (hash.Hash, error) {
+	switch format {
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("unknown git object format %s", format)
+}
+
+// writes the git object header of a `kind` object of `size` bytes
+func writeGitHeader(h hash.Hash, kind string, size int64) {
+	_, _ = h.Write([]byte(kind + " " + strconv.FormatInt(size, 10) + "\x00"))
+}
+
+// GitBlob returns the blob id of the file `name` in git object format `format`, "sha1" or "sha256"
+func GitBlob(name, format string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	sum, err := GitBlobReader(f, fi.Size(), format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return sum, nil
+}
+
+// GitBlobReader returns the blob id of the `size` bytes read from `r`, in git object format
+// `format`, "sha1" or "sha256". The header holds the size, so it must be known up front
+func GitBlobReader(r io.Reader, size int64, format string) ([]byte, error) {
+	h, err := newGitHash(format)
+	if err != nil {
+		return nil, err
+	}
+	writeGitHeader(h, "blob", size)
+	n, err := io.Copy(h, io.LimitReader(r, size+1))
+	if err != nil {
+		return nil, err
+	}
+	if n != size {
+		return nil, fmt.Errorf("read %d bytes, expected %d", n, size)
+	}
+	return h.Sum(nil), nil
+}
+
+// GitTree returns the tree id of the directory `dir` in git object format `format`, "sha1"
+// or "sha256", as if all files were added. As in git, empty directories and .git are left out
+func GitTree(dir, format string) ([]byte, error) {
+	if _, err := newGitHash(format); err != nil {
+		return nil, err
+	}
+	id, err := gitTree(dir, format)
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		// the empty tree
+		h, _ := newGitHash(format)
+		writeGitHeader(h, "tree", 0)
+		return h.Sum(nil), nil
+	}
+	return id, nil
+}
+
+type gitTreeEntry struct {
+	mode string
+	name string
+	id   []byte
+}
+
+// returns the tree id of `dir`, or nil if it holds no files
+func gitTree(dir, format string) ([]byte, error) {
+	list, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []gitTreeEntry
+	for _, fi := range list {
+		if fi.Name() == ".git" {
+			continue
+		}
+		name := filepath.Join(dir, fi.Name())
+		e := gitTreeEntry{name: fi.Name()}
+		switch {
+		case fi.IsDir():
+			e.mode = gitModeTree
+			e.id, err = gitTree(name, format)
+		case fi.Mode()&os.ModeSymlink != 0:
+			e.mode = gitModeSymlink
+			e.id, err = gitSymlink(name, format)
+		case fi.Mode().IsRegular():
+			e.mode = gitModeFile
+			if fi.Mode()&0111 != 0 {
+				e.mode = gitModeExecutable
+			}
+			e.id, err = GitBlob(name, format)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.id != nil {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	// entries are sorted by name, with a / after the name of trees
+	sortName := func(e gitTreeEntry) string {
+		if e.mode == gitModeTree {
+			return e.name + "/"
+		}
+		return e.name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortName(entries[i]) < sortName(entries[j])
+	})
+
+	var tree []byte
+	for _, e := range entries {
+		tree = append(tree, e.mode+" "+e.name+"\x00"...)
+		tree = append(tree, e.id...)
+	}
+	h, _ := newGitHash(format)
+	writeGitHeader(h, "tree", int64(len(tree)))
+	_, _ = h.Write(tree)
+	return h.Sum(nil), nil
+}
+
+// returns the blob id of the target of symlink `name`
+func gitSymlink(name, format string) ([]byte, error) {
+	target, err := os.Readlink(name)
+	if err != nil {
+		return nil, err
+	}
+	h, _ := newGitHash(format)
+	writeGitHeader(h, "blob", int64(len(target)))
+	_, _ = h.Write([]byte(filepath.ToSlash(target)))
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,89 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitBlob(t *testing.T) {
+	// from git hash-object, with --object-format=sha256 for sha256
+	for _, tc := range []struct {
+		algo     string
+		input    string
+		expected string
+	}{
+		{"git-blob", "", "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"},
+		{"git-blob", fox, "ff3bb63948b4b24796d2acd259915f2a9d972638"},
+		{"git-blob-sha256", "", "473a0f4c3be8a93681a267e3b1e9a7dcda1185436fe141f7749120a303721813"},
+		{"git-blob-sha256", fox, "76328de424bbba7d238899393860b8c650698851487e32c514bacc201f04dc81"},
+	} {
+		format := strings.TrimPrefix(strings.TrimPrefix(tc.algo, "git-blob"), "-")
+		if format == "" {
+			format = "sha1"
+		}
+		sum, err := GitBlobReader(strings.NewReader(tc.input), int64(len(tc.input)), format)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo)
+
+		name := filepath.Join(t.TempDir(), "blob")
+		assert.Equal(t, nil, ioutil.WriteFile(name, []byte(tc.input), 0644))
+		sum, err = GitBlob(name, format)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo)
+	}
+
+	// the input must be of the given size
+	for _, size := range []int64{int64(len(fox)) - 1, int64(len(fox)) + 1} {
+		_, err := GitBlobReader(strings.NewReader(fox), size, "sha1")
+		assert.NotEqual(t, nil, err, size)
+	}
+	_, err := GitBlobReader(strings.NewReader(fox), int64(len(fox)), "md5")
+	assert.NotEqual(t, nil, err)
+
+	// git-blob has no streaming form
+	_, err = NewHash("git-blob")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGitTree(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, "b"), 0755))
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/master\n"), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte(fox), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "b", "c.txt"), []byte("hello\n"), 0644))
+	// "b.txt" sorts before the tree "b", which sorts as "b/"
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "b.txt"), []byte("x"), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0755))
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	// from git write-tree, with --object-format=sha256 for sha256
+	for _, tc := range []struct {
+		format   string
+		dir      string
+		expected string
+	}{
+		{"sha1", dir, "f47cf6c5185dfab88293ba166c8b51b0871067d0"},
+		{"sha1", filepath.Join(dir, "b"), "2bcada35da78a7011b5497fcf851bb11e2353a39"},
+		{"sha1", filepath.Join(dir, "empty"), "4b825dc642cb6eb9a060e54bf8d69288fbee4904"},
+		{"sha256", dir, "b21524c6e0674d8d50f69d9b97afdf998f292e4a48e1f6530886fbf21e4067b9"},
+		{"sha256", filepath.Join(dir, "b"), "eb82cb9aab7cd949739b5f4747033f28edc659c6ddc25d2b40ad0a25c9c576c7"},
+		{"sha256", filepath.Join(dir, "empty"), "6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321"},
+	} {
+		sum, err := GitTree(tc.dir, tc.format)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.dir)
+	}
+
+	_, err := GitTree(dir, "md5")
+	assert.NotEqual(t, nil, err)
+}
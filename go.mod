@@ -3,11 +3,13 @@ module github.com/martinlindhe/gohash
 go 1.15
 
 require (
+	github.com/OneOfOne/xxhash v1.2.8
 	github.com/alecthomas/kingpin v2.2.6+incompatible
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751 // indirect
 	github.com/alecthomas/units v0.0.0-20231202071711-9a357b53e9c9 // indirect
 	github.com/bproctor/base91 v0.0.0-20160902125316-7de6f1dd34e8
 	github.com/cespare/xxhash v1.1.0
+	github.com/cloudflare/circl v1.3.7
 	github.com/cxmcc/tiger v0.0.0-20170524142333-bde35e2713d7
 	github.com/dchest/blake256 v1.1.0
 	github.com/dchest/blake2b v1.0.0
@@ -15,9 +17,12 @@ require (
 	github.com/dchest/blake512 v1.0.0
 	github.com/dchest/siphash v1.2.3
 	github.com/dchest/skein v0.0.0-20171112102903-d7f1022db390
+	github.com/dgryski/go-farm v0.0.0-20200201041132-a6ae2369ad13
+	github.com/ebfe/keccak v0.0.0-20150115210727-5cc570678d1b
+	github.com/emmansun/gmsm v0.15.5
 	github.com/fatih/color v1.16.0
+	github.com/go-faster/city v1.0.1
 	github.com/google/gofuzz v1.2.0
-	github.com/howeyc/crc16 v0.0.0-20171223171357-2b2a61e366a6
 	github.com/htruong/go-md2 v0.0.0-20170914203617-c69905b63f6f
 	github.com/jbenet/go-base58 v0.0.0-20150317085156-6237cf65f3a6
 	github.com/jzelinskie/whirlpool v0.0.0-20201016144138-0675e54bb004
@@ -25,15 +30,16 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/martinlindhe/base36 v1.1.1
 	github.com/martinlindhe/bubblebabble v0.0.0-20211021102107-4f319e63ba5e
-	github.com/martinlindhe/crc24 v0.0.0-20220926100321-c4090278f9dc
 	github.com/martinlindhe/gogost v0.0.0-20170914195721-31862914ae20
 	github.com/martinlindhe/uu v0.0.0-20211021104116-02a47cb3d5f1
 	github.com/mattn/go-isatty v0.0.20
-	github.com/mewpkg/hashutil v1.0.1
+	github.com/minio/highwayhash v1.0.2
+	github.com/spaolacci/murmur3 v1.1.0
 	github.com/stretchr/testify v1.8.4
 	github.com/tilinna/z85 v1.0.0
+	github.com/zeebo/blake3 v0.2.4
+	github.com/zeebo/wyhash v0.0.1
+	github.com/zeebo/xxh3 v1.0.2
 	golang.org/x/crypto v0.18.0
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
-	gopkg.in/mewpkg/hashutil.v1 v1.0.1 // indirect
-	lukechampine.com/blake3 v1.2.1
 )
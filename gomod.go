@@ -0,0 +1,267 @@
+package gohash
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Go module hashes of go.sum, the "h1:" hash of golang.org/x/mod/sumdb/dirhash. This is the
+// base64 sha256 of a summary with a line "<hex sha256>  <name>\n" for each file, sorted by name.
+// Files are named "<module>@<version>/<path>", and go.mod files of go.sum as "go.mod"
+
+// returns the h1 hash of `files`, opening each with `open`
+func goModHash1(files []string, open func(string) (io.ReadCloser, error)) (string, error) {
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+	h := sha256.New()
+	for _, file := range files {
+		if strings.Contains(file, "\n") {
+			return "", fmt.Errorf("file names with newlines are not supported")
+		}
+		r, err := open(file)
+		if err != nil {
+			return "", err
+		}
+		hf := sha256.New()
+		_, err = io.Copy(hf, r)
+		r.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%x  %s\n", hf.Sum(nil), file)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// GoModHashDir returns the h1 hash of the directory `dir`, with file names prefixed by
+// `prefix`, which is "<module>@<version>"
+func GoModHashDir(dir, prefix string) (string, error) {
+	dir = filepath.Clean(dir)
+	var files []string
+	err := filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		if file == dir {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files = append(files, prefix+"/"+filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return goModHash1(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, prefix+"/"))))
+	})
+}
+
+// GoModHashZip returns the h1 hash of the module zip `name`, whose file names hold the prefix
+func GoModHashZip(name string) (string, error) {
+	z, err := zip.OpenReader(name)
+	if err != nil {
+		return "", err
+	}
+	defer z.Close()
+	var files []string
+	entries := make(map[string]*zip.File)
+	for _, f := range z.File {
+		files = append(files, f.Name)
+		entries[f.Name] = f
+	}
+	return goModHash1(files, func(name string) (io.ReadCloser, error) {
+		return entries[name].Open()
+	})
+}
+
+// GoModHashGoMod returns the h1 hash of the go.mod file `name`, as the "/go.mod" lines of go.sum
+func GoModHashGoMod(name string) (string, error) {
+	return goModHash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return os.Open(name)
+	})
+}
+
+// escapes the module path or version `s` as in the module cache, where upper case letters
+// are written as "!" and the lower case letter
+func goModEscape(s string) (string, error) {
+	var sb strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '!' || c >= 0x80:
+			return "", fmt.Errorf("invalid module path or version %q", s)
+		case c >= 'A' && c <= 'Z':
+			sb.WriteString("!" + string(c-'A'+'a'))
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+// GoModCache returns the module cache directory, from GOMODCACHE or the first GOPATH
+func GoModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := filepath.SplitList(os.Getenv("GOPATH"))
+	if len(gopath) > 0 && gopath[0] != "" {
+		return filepath.Join(gopath[0], "pkg", "mod")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+// GoSumResult is the verification of a go.sum line against the module cache
+type GoSumResult struct {
+	Module string
+	// the version, with a "/go.mod" suffix for the hash of the go.mod file
+	Version  string
+	Expected string
+	// the hash in the module cache, empty if the module is not in the cache
+	Actual string
+}
+
+// OK returns true if the module is in the cache and its hash matches
+func (r GoSumResult) OK() bool {
+	return r.Actual != "" && r.Actual == r.Expected
+}
+
+// VerifyGoSum verifies each line of go.sum `r` against the module cache `modCache`.
+// Modules are hashed from the extracted directory if present, otherwise from the
+// downloaded zip. Modules which are not in the cache have an empty Actual hash
+func VerifyGoSum(r io.Reader, modCache string) ([]GoSumResult, error) {
+	var res []GoSumResult
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("go.sum line %d: expected module, version and hash", line)
+		}
+		result := GoSumResult{Module: fields[0], Version: fields[1], Expected: fields[2]}
+		actual, err := goModCacheHash(modCache, result.Module, result.Version)
+		if err != nil {
+			return nil, fmt.Errorf("go.sum line %d: %v", line, err)
+		}
+		result.Actual = actual
+		res = append(res, result)
+	}
+	return res, scanner.Err()
+}
+
+// returns the h1 hash of `module` at `version` in the module cache, or empty if not present
+func goModCacheHash(modCache, module, version string) (string, error) {
+	escModule, err := goModEscape(module)
+	if err != nil {
+		return "", err
+	}
+	goMod := strings.HasSuffix(version, "/go.mod")
+	version = strings.TrimSuffix(version, "/go.mod")
+	escVersion, err := goModEscape(version)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(escModule, "..") || strings.Contains(escVersion, "..") || strings.ContainsAny(escVersion, "/\\") {
+		return "", fmt.Errorf("invalid module path or version %s %s", module, version)
+	}
+	download := filepath.Join(modCache, "cache", "download", filepath.FromSlash(escModule), "@v")
+
+	var candidates []func() (string, error)
+	if goMod {
+		name := filepath.Join(download, escVersion+".mod")
+		candidates = append(candidates, func() (string, error) { return GoModHashGoMod(name) })
+	} else {
+		dir := filepath.Join(modCache, filepath.FromSlash(escModule)+"@"+escVersion)
+		zip := filepath.Join(download, escVersion+".zip")
+		candidates = append(candidates,
+			func() (string, error) { return GoModHashDir(dir, module+"@"+version) },
+			func() (string, error) { return GoModHashZip(zip) })
+	}
+	for _, hash := range candidates {
+		sum, err := hash()
+		if os.IsNotExist(err) {
+			continue
+		}
+		return sum, err
+	}
+	return "", nil
+}
+
+// returns the "<module>@<version>" prefix of a module cache directory such as
+// "github.com/!burnt!sushi/toml@v1.3.2", or empty if `dir` is not a module directory
+func goModCachePrefix(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	base := filepath.Base(abs)
+	i := strings.LastIndex(base, "@")
+	if i <= 0 {
+		return ""
+	}
+	// the module path is relative to the module cache
+	rel, err := filepath.Rel(GoModCache(), filepath.Dir(abs))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	module := base[:i]
+	if rel != "." {
+		module = filepath.ToSlash(rel) + "/" + module
+	}
+	return goModUnescape(module) + "@" + goModUnescape(base[i+1:])
+}
+
+func goModUnescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '!' && i+1 < len(s) {
+			i++
+			sb.WriteByte(s[i] - 'a' + 'A')
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// GoModHash returns the h1 hash of a module directory or zip `name`. The "<module>@<version>"
+// prefix of a directory is `prefix`, or taken from its path in the module cache if empty
+func GoModHash(name, prefix string) (string, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		if prefix != "" {
+			return "", fmt.Errorf("a module zip holds its prefix")
+		}
+		if filepath.Base(name) == "go.mod" || strings.HasSuffix(name, ".mod") {
+			return GoModHashGoMod(name)
+		}
+		return GoModHashZip(name)
+	}
+	if prefix == "" {
+		prefix = goModCachePrefix(name)
+	}
+	if prefix == "" {
+		return "", fmt.Errorf("%s is not in the module cache, the module@version prefix is required", name)
+	}
+	return GoModHashDir(name, prefix)
+}
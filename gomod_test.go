@@ -0,0 +1,118 @@
+package gohash
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testGoMod          = "module example.com/M\n"
+	testGoModHash      = "h1:CunZ2lFoa484vW4ZW4xnQnMPzlVFX2A7xr2oO4q7/kg="
+	testGoModDirHash   = "h1:FoOKvR+vgZk+FNFkXHH0FQlIMxyvh7fsyDHHmvo88AY="
+	testGoModulePrefix = "example.com/M@v1.0.0"
+)
+
+// writes the module example.com/M at v1.0.0 to module cache `modCache`, as extracted
+// directory, zip and go.mod
+func writeTestModule(t *testing.T, modCache string) {
+	dir := filepath.Join(modCache, "example.com", "!m@v1.0.0")
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, "a"), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(testGoMod), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "a", "b.go"), []byte("package b\n"), 0644))
+
+	download := filepath.Join(modCache, "cache", "download", "example.com", "!m", "@v")
+	assert.Equal(t, nil, os.MkdirAll(download, 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(download, "v1.0.0.mod"), []byte(testGoMod), 0644))
+
+	f, err := os.Create(filepath.Join(download, "v1.0.0.zip"))
+	assert.Equal(t, nil, err)
+	zw := zip.NewWriter(f)
+	for _, file := range []struct{ name, data string }{
+		{testGoModulePrefix + "/go.mod", testGoMod},
+		{testGoModulePrefix + "/a/b.go", "package b\n"},
+	} {
+		w, err := zw.Create(file.name)
+		assert.Equal(t, nil, err)
+		_, err = w.Write([]byte(file.data))
+		assert.Equal(t, nil, err)
+	}
+	assert.Equal(t, nil, zw.Close())
+	assert.Equal(t, nil, f.Close())
+}
+
+func TestGoModHash(t *testing.T) {
+	modCache := t.TempDir()
+	writeTestModule(t, modCache)
+	dir := filepath.Join(modCache, "example.com", "!m@v1.0.0")
+	download := filepath.Join(modCache, "cache", "download", "example.com", "!m", "@v")
+
+	sum, err := GoModHashDir(dir, testGoModulePrefix)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, testGoModDirHash, sum)
+
+	sum, err = GoModHashZip(filepath.Join(download, "v1.0.0.zip"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, testGoModDirHash, sum)
+
+	sum, err = GoModHashGoMod(filepath.Join(dir, "go.mod"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, testGoModHash, sum)
+
+	// the prefix is taken from the path in the module cache
+	os.Setenv("GOMODCACHE", modCache)
+	defer os.Unsetenv("GOMODCACHE")
+	assert.Equal(t, testGoModulePrefix, goModCachePrefix(dir))
+	sum, err = GoModHash(dir, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, testGoModDirHash, sum)
+
+	_, err = GoModHash(t.TempDir(), "")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGoModEscape(t *testing.T) {
+	s, err := goModEscape("github.com/BurntSushi/toml")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "github.com/!burnt!sushi/toml", s)
+	assert.Equal(t, "github.com/BurntSushi/toml", goModUnescape(s))
+
+	_, err = goModEscape("example.com/!m")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestVerifyGoSum(t *testing.T) {
+	modCache := t.TempDir()
+	writeTestModule(t, modCache)
+
+	gosum := "example.com/M v1.0.0 " + testGoModDirHash + "\n" +
+		"example.com/M v1.0.0/go.mod " + testGoModHash + "\n" +
+		"\n" +
+		"example.com/M v1.0.1 " + testGoModDirHash + "\n" +
+		"example.com/M v1.0.0 h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=\n"
+	res, err := VerifyGoSum(strings.NewReader(gosum), modCache)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []GoSumResult{
+		{Module: "example.com/M", Version: "v1.0.0", Expected: testGoModDirHash, Actual: testGoModDirHash},
+		{Module: "example.com/M", Version: "v1.0.0/go.mod", Expected: testGoModHash, Actual: testGoModHash},
+		{Module: "example.com/M", Version: "v1.0.1", Expected: testGoModDirHash},
+		{Module: "example.com/M", Version: "v1.0.0", Expected: "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", Actual: testGoModDirHash},
+	}, res)
+	assert.Equal(t, []bool{true, true, false, false}, []bool{res[0].OK(), res[1].OK(), res[2].OK(), res[3].OK()})
+
+	// without the extracted directory, the zip is hashed
+	assert.Equal(t, nil, os.RemoveAll(filepath.Join(modCache, "example.com", "!m@v1.0.0")))
+	res, err = VerifyGoSum(strings.NewReader(gosum), modCache)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res[0].OK())
+
+	_, err = VerifyGoSum(strings.NewReader("example.com/M v1.0.0\n"), modCache)
+	assert.NotEqual(t, nil, err)
+	_, err = VerifyGoSum(strings.NewReader("example.com/M ../../v1 h1:x\n"), modCache)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,124 @@
+package gohash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// HAS-160 is the Korean standard hash TTAS.KO-12.0011/R2, similar to SHA-1
+// but with little endian words and a message expansion per round
+
+const (
+	has160Size      = 20
+	has160BlockSize = 64
+)
+
+var (
+	has160K     = [4]uint32{0x00000000, 0x5a827999, 0x6ed9eba1, 0x8f1bbcdc}
+	has160Rot1  = [20]int{5, 11, 7, 15, 6, 13, 8, 14, 7, 12, 9, 11, 8, 15, 6, 12, 9, 14, 5, 13}
+	has160Rot2  = [4]int{10, 17, 25, 30}
+	has160Order = [4][20]int{
+		{18, 0, 1, 2, 3, 19, 4, 5, 6, 7, 16, 8, 9, 10, 11, 17, 12, 13, 14, 15},
+		{18, 3, 6, 9, 12, 19, 15, 2, 5, 8, 16, 11, 14, 1, 4, 17, 7, 10, 13, 0},
+		{18, 12, 5, 14, 7, 19, 0, 9, 2, 11, 16, 4, 13, 6, 15, 17, 8, 1, 10, 3},
+		{18, 7, 2, 13, 8, 19, 3, 14, 9, 4, 16, 15, 10, 5, 0, 17, 11, 6, 1, 12},
+	}
+	// the message words xored into the extra words 16 to 19, per round
+	has160Expand = [4][4][4]int{
+		{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9, 10, 11}, {12, 13, 14, 15}},
+		{{3, 6, 9, 12}, {15, 2, 5, 8}, {11, 14, 1, 4}, {7, 10, 13, 0}},
+		{{12, 5, 14, 7}, {0, 9, 2, 11}, {4, 13, 6, 15}, {8, 1, 10, 3}},
+		{{7, 2, 13, 8}, {3, 14, 9, 4}, {15, 10, 5, 0}, {11, 6, 1, 12}},
+	}
+)
+
+type has160 struct {
+	h      [5]uint32
+	x      [has160BlockSize]byte
+	nx     int
+	length uint64
+}
+
+func newHAS160() hash.Hash {
+	d := &has160{}
+	d.Reset()
+	return d
+}
+
+func (d *has160) Reset() {
+	d.h = [5]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0}
+	d.nx = 0
+	d.length = 0
+}
+
+func (d *has160) Size() int      { return has160Size }
+func (d *has160) BlockSize() int { return has160BlockSize }
+
+func (d *has160) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+		if d.nx == has160BlockSize {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+	}
+	for len(p) >= has160BlockSize {
+		d.block(p[:has160BlockSize])
+		p = p[has160BlockSize:]
+	}
+	d.nx += copy(d.x[:], p)
+	return n, nil
+}
+
+func (d *has160) Sum(b []byte) []byte {
+	// padding as MD5, on a copy so the state is unchanged
+	d0 := *d
+	length := d0.length
+	pad := make([]byte, has160BlockSize+8)
+	pad[0] = 0x80
+	n := (55-int(length%has160BlockSize)+has160BlockSize)%has160BlockSize + 1
+	binary.LittleEndian.PutUint64(pad[n:], length<<3)
+	_, _ = d0.Write(pad[:n+8])
+
+	var sum [has160Size]byte
+	for i, v := range d0.h {
+		binary.LittleEndian.PutUint32(sum[i*4:], v)
+	}
+	return append(b, sum[:]...)
+}
+
+func (d *has160) block(p []byte) {
+	var x [20]uint32
+	for i := 0; i < 16; i++ {
+		x[i] = binary.LittleEndian.Uint32(p[i*4:])
+	}
+	a, b, c, dd, e := d.h[0], d.h[1], d.h[2], d.h[3], d.h[4]
+	for round := 0; round < 4; round++ {
+		for i, words := range has160Expand[round] {
+			x[16+i] = x[words[0]] ^ x[words[1]] ^ x[words[2]] ^ x[words[3]]
+		}
+		for j := 0; j < 20; j++ {
+			var f uint32
+			switch round {
+			case 0:
+				f = (b & c) | (^b & dd)
+			case 2:
+				f = c ^ (b | ^dd)
+			default:
+				f = b ^ c ^ dd
+			}
+			t := bits.RotateLeft32(a, has160Rot1[j]) + f + e + x[has160Order[round][j]] + has160K[round]
+			e, dd, c, b, a = dd, c, bits.RotateLeft32(b, has160Rot2[round]), a, t
+		}
+	}
+	d.h[0] += a
+	d.h[1] += b
+	d.h[2] += c
+	d.h[3] += dd
+	d.h[4] += e
+}
@@ -13,6 +13,7 @@ type Hasher struct {
 	prefix      string
 	suffix      string
 	expected    []byte
+	expectedErr error
 	minLength   int
 	maxLength   int
 	allowedKeys []byte
@@ -28,15 +29,26 @@ func NewHasher() *Hasher {
 	return &Hasher{}
 }
 
-// Algo sets the hash algorithm ("sha1", "sha512")
+// Algo sets the hash algorithm ("sha1", "sha512", "blake2b:output=20")
 func (h *Hasher) Algo(algo string) {
+	// parameters are kept as is
+	params := ""
+	if pos := strings.Index(algo, ":"); pos != -1 {
+		algo, params = algo[:pos], algo[pos:]
+	}
 	algo = strings.Replace(algo, "_", "-", -1)
 	algo = strings.ToLower(algo)
-	h.algo = algo
+	h.algo = algo + params
 }
 
-// ExpectedHash sets the expected hash
+// ExpectedHash sets the expected hash, in hex or as a crypt(3) string which also sets the algorithm
 func (h *Hasher) ExpectedHash(expected string) {
+	if strings.HasPrefix(expected, "$") {
+		var algo string
+		algo, h.expected, h.expectedErr = ParseCrypt(expected)
+		h.Algo(algo)
+		return
+	}
 	tmp, _ := decodeHex(strings.NewReader(expected))
 	h.expected = tmp[:]
 }
@@ -53,10 +65,7 @@ func (h *Hasher) Reverse(b bool) {
 }
 
 // Prefix sets a fixed prefix
-func (h *Hasher) Prefix(s string) {
-	h.prefix = s
-	panic(fmt.Errorf("TODO impl Prefix for Hasher"))
-}
+func (h *Hasher) Prefix(s string) { h.prefix = s }
 
 // Suffix sets a fixed suffix
 func (h *Hasher) Suffix(s string) { h.suffix = s }
@@ -82,13 +91,16 @@ func (h *Hasher) FindSequential() (string, error) {
 		return "", err
 	}
 
-	h.buffer = make([]byte, h.minLength)
+	// the mutation is between prefix and suffix
+	start := len(h.prefix)
+	end := start + h.minLength
+	h.buffer = append([]byte(h.prefix), make([]byte, h.minLength)...)
 
 	firstAllowedKey := h.allowedKeys[0]
 	lastAllowedKey := h.allowedKeys[len(h.allowedKeys)-1]
 
 	// create initial mutation
-	for x := 0; x < h.minLength; x++ {
+	for x := start; x < end; x++ {
 		if h.reverse {
 			h.buffer[x] = lastAllowedKey
 		} else {
@@ -108,7 +120,7 @@ func (h *Hasher) FindSequential() (string, error) {
 		}
 
 		// update mutation
-		for roller := h.minLength - 1; roller >= 0; roller-- {
+		for roller := end - 1; roller >= start; roller-- {
 			if h.reverse {
 				if buf[roller] == firstAllowedKey {
 					buf[roller] = lastAllowedKey
@@ -146,13 +158,16 @@ func (h *Hasher) FindRandom() (string, error) {
 		return "", err
 	}
 
-	h.buffer = make([]byte, h.minLength)
+	// the mutation is between prefix and suffix
+	start := len(h.prefix)
+	end := start + h.minLength
+	h.buffer = append([]byte(h.prefix), make([]byte, h.minLength)...)
 
 	firstAllowedKey := h.allowedKeys[0]
 	allowedKeysLen := len(h.allowedKeys)
 
 	// create initial mutation
-	for x := 0; x < h.minLength; x++ {
+	for x := start; x < end; x++ {
 		h.buffer[x] = firstAllowedKey
 	}
 
@@ -167,7 +182,7 @@ func (h *Hasher) FindRandom() (string, error) {
 		}
 
 		// update mutation of first letters
-		for roller := 0; roller < h.minLength; roller++ {
+		for roller := start; roller < end; roller++ {
 			buf[roller] = h.allowedKeys[rand.Intn(allowedKeysLen)]
 		}
 
@@ -180,6 +195,10 @@ func (h *Hasher) FindRandom() (string, error) {
 
 func (h *Hasher) verify() error {
 
+	if h.expectedErr != nil {
+		return h.expectedErr
+	}
+
 	if len(h.allowedKeys) == 0 {
 		return fmt.Errorf("allowedKeys unset")
 	}
@@ -195,13 +214,19 @@ func (h *Hasher) verify() error {
 	keyBitSize := len(h.expected) * 8
 	expectedBitSize := len(h.expected) * 8
 
-	if requiredBitSize, ok := algos[h.algo]; ok {
-		if keyBitSize != requiredBitSize {
-			return fmt.Errorf("expectedHash is wrong size, should be %d bit, is %d",
-				requiredBitSize, expectedBitSize)
-		}
-	} else {
-		return fmt.Errorf("unknown algo %s", h.algo)
+	// use the expected size for algorithms with variable output size
+	var err error
+	if h.algo, err = withOutputSize(h.algo, len(h.expected)); err != nil {
+		return err
+	}
+
+	algo, err := NewHash(h.algo)
+	if err != nil {
+		return err
+	}
+	if requiredBitSize := algo.Size() * 8; keyBitSize != requiredBitSize {
+		return fmt.Errorf("expectedHash is wrong size, should be %d bit, is %d",
+			requiredBitSize, expectedBitSize)
 	}
 
 	return nil
@@ -62,6 +62,31 @@ func TestSequentialHasher(t *testing.T) {
 	}
 }
 
+func TestSequentialHasherParams(t *testing.T) {
+	hasher := NewHasher()
+	hasher.Algo("BLAKE2b:output=4,person=676f68617368")
+	hasher.Length(3)
+	hasher.AllowedKeys("holej")
+	hasher.ExpectedHash("75e57b67")
+
+	res, err := hasher.FindSequential()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hej", string(res))
+}
+
+func TestSequentialHasherVariableSize(t *testing.T) {
+	// output size is taken from the expected hash
+	hasher := NewHasher()
+	hasher.Algo("shake256")
+	hasher.Length(3)
+	hasher.AllowedKeys("holej")
+	hasher.ExpectedHash("315f7574a7")
+
+	res, err := hasher.FindSequential()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hej", string(res))
+}
+
 func TestHashSequential(t *testing.T) {
 	hasher := NewHasher()
 	hasher.Algo("sha512")
@@ -107,6 +132,27 @@ func TestHashReverse(t *testing.T) {
 	assert.Equal(t, "zzzzzzzzzzzzzzww.onion", string(res))
 }
 
+func TestHashPrefix(t *testing.T) {
+	hasher := NewHasher()
+	hasher.Algo("sha1")
+	hasher.AllowedKeys(allowedOnion)
+	hasher.Prefix("www.")
+	hasher.Suffix(".onion")
+	hasher.ExpectedHash("ad3e280b15b7898f8d0790764048d79020cb0b05")
+	hasher.Length(2)
+
+	res, err := hasher.FindSequential()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "www.ab.onion", string(res))
+
+	hasher.Suffix("")
+	hasher.Algo("md5")
+	hasher.ExpectedHash("c1d21472b1f761fe8c8f0e3f7417285c")
+	res, err = hasher.FindRandom()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "www.ab", string(res))
+}
+
 // benchmarks given key length and print a prediction based on it
 func BenchmarkSha1Speed(*testing.B) {
 
@@ -119,3 +165,31 @@ func BenchmarkSha1Speed(*testing.B) {
 
 	_, _ = hasher.FindSequential()
 }
+
+func TestSequentialHasherPassword(t *testing.T) {
+	hasher := NewHasher()
+	hasher.Algo("pbkdf2-sha1:rounds=2,salt=73616c74")
+	hasher.Length(2)
+	hasher.AllowedKeys("abcdefghij")
+	hasher.ExpectedHash("a95d885c129bcf85fd0e45b6855bdbced756828c")
+
+	res, err := hasher.FindSequential()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hi", string(res))
+}
+
+func TestSequentialHasherCrypt(t *testing.T) {
+	// the crypt string gives the algorithm
+	hasher := NewHasher()
+	hasher.Length(3)
+	hasher.AllowedKeys("holej")
+	hasher.ExpectedHash("$apr1$ab$t/mI5AY4VGXzYF.z.PdDX0")
+
+	res, err := hasher.FindSequential()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "hej", string(res))
+
+	hasher.ExpectedHash("$apr1$ab")
+	_, err = hasher.FindSequential()
+	assert.NotEqual(t, nil, err)
+}
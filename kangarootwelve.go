@@ -0,0 +1,251 @@
+package gohash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/bits"
+
+	"github.com/cloudflare/circl/xof/k12"
+	"golang.org/x/crypto/sha3"
+)
+
+// TurboSHAKE and KangarooTwelve use Keccak with 12 rounds instead of 24,
+// see https://www.rfc-editor.org/rfc/rfc9861
+
+const (
+	turboShakeRounds = 12
+	// the default domain separation byte
+	turboShakeDomain = 0x1f
+)
+
+var keccakRoundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// keccakP1600 is the Keccak-p[1600] permutation, using the last `rounds` rounds of Keccak-f[1600]
+func keccakP1600(s *[25]uint64, rounds int) {
+	a0, a1, a2, a3, a4, a5, a6, a7, a8, a9, a10, a11, a12, a13, a14, a15, a16, a17, a18, a19, a20, a21, a22, a23, a24 := s[0], s[1], s[2], s[3], s[4], s[5], s[6], s[7], s[8], s[9], s[10], s[11], s[12], s[13], s[14], s[15], s[16], s[17], s[18], s[19], s[20], s[21], s[22], s[23], s[24]
+	for _, rc := range keccakRoundConstants[24-rounds:] {
+		// theta
+		c0 := a0 ^ a5 ^ a10 ^ a15 ^ a20
+		c1 := a1 ^ a6 ^ a11 ^ a16 ^ a21
+		c2 := a2 ^ a7 ^ a12 ^ a17 ^ a22
+		c3 := a3 ^ a8 ^ a13 ^ a18 ^ a23
+		c4 := a4 ^ a9 ^ a14 ^ a19 ^ a24
+		d0 := c4 ^ bits.RotateLeft64(c1, 1)
+		d1 := c0 ^ bits.RotateLeft64(c2, 1)
+		d2 := c1 ^ bits.RotateLeft64(c3, 1)
+		d3 := c2 ^ bits.RotateLeft64(c4, 1)
+		d4 := c3 ^ bits.RotateLeft64(c0, 1)
+		// rho and pi
+		b0 := a0 ^ d0
+		b1 := bits.RotateLeft64(a6^d1, 44)
+		b2 := bits.RotateLeft64(a12^d2, 43)
+		b3 := bits.RotateLeft64(a18^d3, 21)
+		b4 := bits.RotateLeft64(a24^d4, 14)
+		b5 := bits.RotateLeft64(a3^d3, 28)
+		b6 := bits.RotateLeft64(a9^d4, 20)
+		b7 := bits.RotateLeft64(a10^d0, 3)
+		b8 := bits.RotateLeft64(a16^d1, 45)
+		b9 := bits.RotateLeft64(a22^d2, 61)
+		b10 := bits.RotateLeft64(a1^d1, 1)
+		b11 := bits.RotateLeft64(a7^d2, 6)
+		b12 := bits.RotateLeft64(a13^d3, 25)
+		b13 := bits.RotateLeft64(a19^d4, 8)
+		b14 := bits.RotateLeft64(a20^d0, 18)
+		b15 := bits.RotateLeft64(a4^d4, 27)
+		b16 := bits.RotateLeft64(a5^d0, 36)
+		b17 := bits.RotateLeft64(a11^d1, 10)
+		b18 := bits.RotateLeft64(a17^d2, 15)
+		b19 := bits.RotateLeft64(a23^d3, 56)
+		b20 := bits.RotateLeft64(a2^d2, 62)
+		b21 := bits.RotateLeft64(a8^d3, 55)
+		b22 := bits.RotateLeft64(a14^d4, 39)
+		b23 := bits.RotateLeft64(a15^d0, 41)
+		b24 := bits.RotateLeft64(a21^d1, 2)
+		// chi and iota
+		a0 = b0 ^ (^b1 & b2)
+		a1 = b1 ^ (^b2 & b3)
+		a2 = b2 ^ (^b3 & b4)
+		a3 = b3 ^ (^b4 & b0)
+		a4 = b4 ^ (^b0 & b1)
+		a5 = b5 ^ (^b6 & b7)
+		a6 = b6 ^ (^b7 & b8)
+		a7 = b7 ^ (^b8 & b9)
+		a8 = b8 ^ (^b9 & b5)
+		a9 = b9 ^ (^b5 & b6)
+		a10 = b10 ^ (^b11 & b12)
+		a11 = b11 ^ (^b12 & b13)
+		a12 = b12 ^ (^b13 & b14)
+		a13 = b13 ^ (^b14 & b10)
+		a14 = b14 ^ (^b10 & b11)
+		a15 = b15 ^ (^b16 & b17)
+		a16 = b16 ^ (^b17 & b18)
+		a17 = b17 ^ (^b18 & b19)
+		a18 = b18 ^ (^b19 & b15)
+		a19 = b19 ^ (^b15 & b16)
+		a20 = b20 ^ (^b21 & b22)
+		a21 = b21 ^ (^b22 & b23)
+		a22 = b22 ^ (^b23 & b24)
+		a23 = b23 ^ (^b24 & b20)
+		a24 = b24 ^ (^b20 & b21)
+		a0 ^= rc
+	}
+	s[0], s[1], s[2], s[3], s[4], s[5], s[6], s[7], s[8], s[9], s[10], s[11], s[12], s[13], s[14], s[15], s[16], s[17], s[18], s[19], s[20], s[21], s[22], s[23], s[24] = a0, a1, a2, a3, a4, a5, a6, a7, a8, a9, a10, a11, a12, a13, a14, a15, a16, a17, a18, a19, a20, a21, a22, a23, a24
+}
+
+// turboShake is TurboSHAKE128 or TurboSHAKE256 with domain separation byte `domain`
+type turboShake struct {
+	a         [25]uint64
+	rate      int
+	domain    byte
+	buf       [rateCShake128]byte
+	n         int
+	squeezing bool
+}
+
+func newTurboShake(security int, domain byte) *turboShake {
+	rate := rateCShake128
+	if security == 256 {
+		rate = rateCShake256
+	}
+	return &turboShake{rate: rate, domain: domain}
+}
+
+func (d *turboShake) Reset() {
+	d.a = [25]uint64{}
+	d.n = 0
+	d.squeezing = false
+}
+
+func (d *turboShake) Size() int      { return (200 - d.rate) / 2 }
+func (d *turboShake) BlockSize() int { return d.rate }
+
+func (d *turboShake) Clone() sha3.ShakeHash {
+	d0 := *d
+	return &d0
+}
+
+func (d *turboShake) permute() {
+	keccakP1600(&d.a, turboShakeRounds)
+}
+
+func (d *turboShake) absorb() {
+	for i := 0; i < d.rate/8; i++ {
+		d.a[i] ^= binary.LittleEndian.Uint64(d.buf[i*8:])
+	}
+	d.permute()
+}
+
+func (d *turboShake) Write(p []byte) (int, error) {
+	if d.squeezing {
+		panic("turboshake: write after read")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		c := copy(d.buf[d.n:d.rate], p)
+		d.n += c
+		p = p[c:]
+		if d.n == d.rate {
+			d.absorb()
+			d.n = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *turboShake) Read(p []byte) (int, error) {
+	if !d.squeezing {
+		// padding is the domain separation byte, zeros and a final 0x80
+		for i := d.n; i < d.rate; i++ {
+			d.buf[i] = 0
+		}
+		d.buf[d.n] ^= d.domain
+		d.buf[d.rate-1] ^= 0x80
+		d.absorb()
+		d.squeezing = true
+		d.n = d.rate
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if d.n == d.rate {
+			for i := 0; i < d.rate/8; i++ {
+				binary.LittleEndian.PutUint64(d.buf[i*8:], d.a[i])
+			}
+			d.permute()
+			d.n = 0
+		}
+		c := copy(p, d.buf[d.n:d.rate])
+		d.n += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *turboShake) Sum(b []byte) []byte {
+	res := make([]byte, d.Size())
+	_, _ = d.Clone().Read(res)
+	return append(b, res...)
+}
+
+// turboshake takes a domain separation byte from 0x01 to 0x7f, and an output size in bytes
+func newTurboShakeWithParams(security int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("output", "domain"); err != nil {
+			return nil, err
+		}
+		output, err := p.output(security / 4)
+		if err != nil {
+			return nil, err
+		}
+		domain, err := p.uint("domain", turboShakeDomain, 8)
+		if err != nil {
+			return nil, err
+		}
+		if domain < 0x01 || domain > 0x7f {
+			return nil, fmt.Errorf("turboshake domain must be 0x01 to 0x7f, is 0x%02x", domain)
+		}
+		return newXOF(newTurboShake(security, byte(domain)), output), nil
+	}
+}
+
+// kangarooTwelve is KangarooTwelve (KT128) with customization string `custom`, which
+// hashes chunks of 8192 bytes in parallel where supported
+type kangarooTwelve struct {
+	k12.State
+}
+
+func newKangarooTwelve(custom []byte) *kangarooTwelve {
+	return &kangarooTwelve{k12.NewDraft10(custom)}
+}
+
+func (d *kangarooTwelve) Size() int      { return 32 }
+func (d *kangarooTwelve) BlockSize() int { return rateCShake128 }
+
+func (d *kangarooTwelve) Clone() sha3.ShakeHash {
+	return &kangarooTwelve{d.State.Clone()}
+}
+
+func (d *kangarooTwelve) Sum(b []byte) []byte {
+	res := make([]byte, d.Size())
+	_, _ = d.Clone().Read(res)
+	return append(b, res...)
+}
+
+// k12 takes a customization string and an output size in bytes
+func newKangarooTwelveWithParams(p HashParams) (hash.Hash, error) {
+	if err := p.allow("output", "custom"); err != nil {
+		return nil, err
+	}
+	output, err := p.output(32)
+	if err != nil {
+		return nil, err
+	}
+	return newXOF(newKangarooTwelve([]byte(p["custom"])), output), nil
+}
@@ -0,0 +1,53 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// returns the repeating pattern 00 01 .. fa of length `n`, used by the RFC 9861 test vectors
+func ptn(n int) string {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i % 0xfb)
+	}
+	return string(buf)
+}
+
+func TestKangarooTwelve(t *testing.T) {
+	// from RFC 9861
+	for _, tc := range []struct {
+		algo     string
+		input    string
+		expected string
+	}{
+		{"k12", "", "1ac2d450fc3b4205d19da7bfca1b37513c0803577ac7167f06fe2ce1f0ef39e5"},
+		{"k12", ptn(17), "6bf75fa2239198db4772e36478f8e19b0f371205f6a9a93a273f51df37122888"},
+		{"k12", ptn(17 * 17), "0c315ebcdedbf61426de7dcf8fb725d1e74675d7f5327a5067f367b108ecb67c"},
+		{"k12", ptn(17 * 17 * 17), "cb552e2ec77d9910701d578b457ddf772c12e322e4ee7fe417f92c758f0d59d0"},
+		{"k12", ptn(17 * 17 * 17 * 17), "8701045e22205345ff4dda05555cbb5c3af1a771c2b89baef37db43d9998b9fe"},
+		{"k12:custom=" + ptn(1), "", "fab658db63e94a246188bf7af69a133045f46ee984c56e3c3328caaf1aa1a583"},
+		{"turboshake128:domain=0x07,output=64", "", "5a223ad30b3b8c66a243048cfced430f54e7529287d15150b973133adfac6a2ffe2708e73061e09a4000168ba9c8ca1813198f7bbed4984b4185f2c2580ee623"},
+		{"turboshake128:domain=6", "\xff", "8ec9c66465ed0d4a6c35d13506718d687a25cb05c74cca1e42501abd83874a67"},
+		{"turboshake128", "", "1e415f1c5983aff2169217277d17bb538cd945a397ddec541f1ce41af2c1b74c"},
+		{"turboshake256", "", "367a329dafea871c7802ec67f905ae13c57695dc2c6663c61035f59a18f8e7db11edc0e12e91ea60eb6b32df06dd7f002fbafabb6e13ec1cc20d995547600db0"},
+	} {
+		calc := NewCalculator(strings.NewReader(tc.input))
+		sum, err := calc.Sum(tc.algo)
+		assert.Equal(t, nil, err, tc.algo)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo)
+	}
+
+	// the last 32 of 10032 bytes, covering the squeezing of several blocks
+	sum, err := NewCalculator(strings.NewReader("")).Sum("turboshake128:domain=0x07,output=10032")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "7593a28020a3c4ae0d605fd61f5eb56eccd27cc3d12ff09f78369772a460c55d", hex.EncodeToString(sum[len(sum)-32:]))
+
+	for _, algo := range []string{"turboshake128:domain=0", "turboshake256:domain=0x80", "k12:name=x"} {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+}
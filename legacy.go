@@ -0,0 +1,184 @@
+package gohash
+
+import (
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/binary"
+	"hash"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// password hashes of legacy applications, without cost parameters. Those taking
+// a username use an empty username by default
+
+const (
+	lmMaxPassword = 14
+	lmMagic       = "KGS!@#$%"
+)
+
+// oracleKey is the fixed DES key of the Oracle 7-10g password hash
+var oracleKey = []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+
+// utf16LE returns `s` in UTF-16, little endian
+func utf16LE(s string) []byte {
+	res := []byte{}
+	for _, c := range utf16.Encode([]rune(s)) {
+		res = append(res, byte(c), byte(c>>8))
+	}
+	return res
+}
+
+// utf16BE returns `s` in UTF-16, big endian
+func utf16BE(s string) []byte {
+	res := []byte{}
+	for _, c := range utf16.Encode([]rune(s)) {
+		res = append(res, byte(c>>8), byte(c))
+	}
+	return res
+}
+
+// ntlm is the MD4 of the UTF-16LE password, used by Windows
+func ntlm(password []byte) []byte {
+	h := md4.New()
+	h.Write(utf16LE(string(password)))
+	return h.Sum(nil)
+}
+
+// lanManager is the LM hash of Windows, where the upper cased password is
+// truncated to 14 bytes, and each half is a DES key to encrypt a fixed string
+func lanManager(password []byte) []byte {
+	key := make([]byte, lmMaxPassword)
+	copy(key, password)
+	for i, c := range key {
+		if c >= 'a' && c <= 'z' {
+			key[i] = c - 'a' + 'A'
+		}
+	}
+	res := []byte{}
+	for i := 0; i < lmMaxPassword; i += 7 {
+		block, _ := des.NewCipher(lmDESKey(key[i : i+7]))
+		sum := make([]byte, des.BlockSize)
+		block.Encrypt(sum, []byte(lmMagic))
+		res = append(res, sum...)
+	}
+	return res
+}
+
+// expands a 7 byte key to a 8 byte DES key, with the parity bits unset
+func lmDESKey(b []byte) []byte {
+	res := make([]byte, 8)
+	for i := range res {
+		// the 7 key bits starting at bit i*7
+		pos := i * 7
+		w := uint16(b[pos/8]) << 8
+		if pos/8+1 < len(b) {
+			w |= uint16(b[pos/8+1])
+		}
+		res[i] = byte(w>>uint(9-pos%8)) << 1
+	}
+	return res
+}
+
+// mysql323 is the OLD_PASSWORD() of MySQL before 4.1, where spaces and tabs are ignored
+func mysql323(password []byte) []byte {
+	nr, nr2, add := uint32(1345345333), uint32(0x12345671), uint32(7)
+	for _, c := range password {
+		if c == ' ' || c == '\t' {
+			continue
+		}
+		tmp := uint32(c)
+		nr ^= ((nr&63)+add)*tmp + nr<<8
+		nr2 += nr2<<8 ^ nr
+		add += tmp
+	}
+	res := make([]byte, 8)
+	binary.BigEndian.PutUint32(res, nr&0x7fffffff)
+	binary.BigEndian.PutUint32(res[4:], nr2&0x7fffffff)
+	return res
+}
+
+// mysql41 is the PASSWORD() of MySQL 4.1 and later, shown as "*" and the hash in upper case hex
+func mysql41(password []byte) []byte {
+	first := sha1.Sum(password)
+	second := sha1.Sum(first[:])
+	return second[:]
+}
+
+// oracleDES is the password hash of Oracle 7 to 10g, the DES-CBC-MAC of the upper
+// cased username and password in UTF-16BE, with the first MAC as key of the second
+func oracleDES(username string, password []byte) []byte {
+	data := utf16BE(strings.ToUpper(username + string(password)))
+	// zero padded to the block size, with at least one block
+	for len(data) == 0 || len(data)%des.BlockSize != 0 {
+		data = append(data, 0)
+	}
+	mac := func(key []byte) []byte {
+		block, _ := des.NewCipher(key)
+		res := make([]byte, len(data))
+		cipher.NewCBCEncrypter(block, make([]byte, des.BlockSize)).CryptBlocks(res, data)
+		return res[len(res)-des.BlockSize:]
+	}
+	return mac(mac(oracleKey))
+}
+
+// mscache is the domain cached credentials of Windows, the MD4 of the NTLM hash
+// and the lower cased username in UTF-16LE
+func mscache(username string, password []byte) []byte {
+	h := md4.New()
+	h.Write(ntlm(password))
+	h.Write(utf16LE(strings.ToLower(username)))
+	return h.Sum(nil)
+}
+
+// postgresMD5 is the md5 password of PostgreSQL, stored as "md5" and the hash in hex
+func postgresMD5(username string, password []byte) []byte {
+	sum := md5.Sum(append(password[:len(password):len(password)], username...))
+	return sum[:]
+}
+
+func newLegacyHash(size int, derive func(password []byte) []byte) func() hash.Hash {
+	return func() hash.Hash {
+		return newBufferedHash(size, derive)
+	}
+}
+
+func newUsernameHash(size int, derive func(username string, password []byte) []byte) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newUsernameHashWithParams(size, derive)(HashParams{})
+		return h
+	}
+}
+
+// takes a username, given as text
+func newUsernameHashWithParams(size int, derive func(username string, password []byte) []byte) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("username"); err != nil {
+			return nil, err
+		}
+		username := p["username"]
+		return newBufferedHash(size, func(password []byte) []byte {
+			return derive(username, password)
+		}), nil
+	}
+}
+
+var legacyHashes = []HashAlgorithm{
+	{Name: "lm", Bits: 128, Family: "legacy", New: newLegacyHash(16, lanManager)},
+	{Name: "mscache", Aliases: []string{"dcc"}, Bits: 128, Family: "legacy", New: newUsernameHash(md4.Size, mscache), NewWithParams: newUsernameHashWithParams(md4.Size, mscache)},
+	{Name: "mysql323", Bits: 64, Family: "legacy", New: newLegacyHash(8, mysql323)},
+	{Name: "mysql41", Aliases: []string{"mysql"}, Bits: 160, Family: "legacy", New: newLegacyHash(sha1.Size, mysql41)},
+	{Name: "ntlm", Bits: 128, Family: "legacy", New: newLegacyHash(md4.Size, ntlm)},
+	{Name: "oracle-des", Aliases: []string{"oracle"}, Bits: 64, Family: "legacy", New: newUsernameHash(des.BlockSize, oracleDES), NewWithParams: newUsernameHashWithParams(des.BlockSize, oracleDES)},
+	{Name: "postgres-md5", Bits: 128, Family: "legacy", New: newUsernameHash(md5.Size, postgresMD5), NewWithParams: newUsernameHashWithParams(md5.Size, postgresMD5)},
+}
+
+func init() {
+	for _, algo := range legacyHashes {
+		mustRegisterHash(algo)
+	}
+}
@@ -0,0 +1,43 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyHashes(t *testing.T) {
+	for _, tc := range []struct {
+		algo     string
+		password string
+		expected string
+	}{
+		{"ntlm", "password", "8846f7eaee8fb117ad06bdd830b7586c"},
+		{"ntlm", "pässwörd", "0553152250ac01adb4213cb9938663e4"},
+		{"lm", "password", "e52cac67419a9a224a3b108f3fa6cb6d"},
+		{"lm", "PASSWORD", "e52cac67419a9a224a3b108f3fa6cb6d"},
+		{"lm", "", "aad3b435b51404eeaad3b435b51404ee"},
+		{"mysql323", "password", "5d2e19393cc5ef67"},
+		{"mysql323", "pass word", "5d2e19393cc5ef67"},
+		{"mysql41", "password", "2470c0c06dee42fd1618bb99005adca2ec9d1e19"},
+		{"oracle-des:username=SCOTT", "TIGER", "f894844c34402b67"},
+		{"oracle-des:username=scott", "tiger", "f894844c34402b67"},
+		{"postgres-md5:username=postgres", "password", "32e12f215ba27cb750c9e093ce4b5127"},
+		// from the hashcat example hashes
+		{"mscache:username=3060147285011", "hashcat", "4dd8965d1d476fa0d026722989a6b772"},
+	} {
+		calc := NewCalculator(strings.NewReader(tc.password))
+		sum, err := calc.Sum(tc.algo)
+		assert.Equal(t, nil, err, tc.algo)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo+" "+tc.password)
+	}
+
+	_, err := NewHash("postgres-md5:salt=00")
+	assert.NotEqual(t, nil, err)
+
+	// legacy hashes have no HMAC
+	_, err = NewHMAC("ntlm", []byte("key"))
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,267 @@
+package gohash
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// LSH is the Korean standard hash KS X 3262, with LSH-256 on 32 bit words and
+// LSH-512 on 64 bit words. See https://seed.kisa.or.kr/kisa/algorithm/EgovLSHInfo.do
+
+const (
+	lshWords      = 16
+	lsh256Steps   = 26
+	lsh512Steps   = 28
+	lsh256Block   = 128
+	lsh512Block   = 256
+	lsh256MaxSize = 32
+	lsh512MaxSize = 64
+)
+
+var (
+	// message word permutation of the message expansion
+	lshTau = [lshWords]int{3, 2, 0, 1, 7, 4, 5, 6, 11, 10, 8, 9, 15, 12, 13, 14}
+	// word permutation after each step
+	lshSigma = [lshWords]int{6, 4, 5, 7, 12, 15, 14, 13, 2, 0, 1, 3, 8, 11, 10, 9}
+
+	lsh256Gamma = [8]int{0, 8, 16, 24, 24, 16, 8, 0}
+	lsh512Gamma = [8]int{0, 16, 32, 48, 8, 24, 40, 56}
+
+	// the step constants of the first step, following steps add the constant rotated by 8 bits
+	lsh256SC0 = [8]uint32{0x917caf90, 0x6c1b10a2, 0x6f352943, 0xcf778243, 0x2ceb7472, 0x29e96ff2, 0x8a9ba428, 0x2eeb2642}
+	lsh512SC0 = [8]uint64{
+		0x97884283c938982a, 0xba1fca93533e2355, 0xc519a2e87aeb1c03, 0x9a0fc95462af17b1,
+		0xfc3dda8ab019a82b, 0x02825d079a895407, 0x79f2d0a7ee06a6f7, 0xd76d15eed9fdf5fe,
+	}
+
+	lsh256SC = lsh256StepConstants()
+	lsh512SC = lsh512StepConstants()
+)
+
+func lsh256StepConstants() [lsh256Steps][8]uint32 {
+	var sc [lsh256Steps][8]uint32
+	sc[0] = lsh256SC0
+	for j := 1; j < lsh256Steps; j++ {
+		for l, v := range sc[j-1] {
+			sc[j][l] = v + bits.RotateLeft32(v, 8)
+		}
+	}
+	return sc
+}
+
+func lsh512StepConstants() [lsh512Steps][8]uint64 {
+	var sc [lsh512Steps][8]uint64
+	sc[0] = lsh512SC0
+	for j := 1; j < lsh512Steps; j++ {
+		for l, v := range sc[j-1] {
+			sc[j][l] = v + bits.RotateLeft64(v, 8)
+		}
+	}
+	return sc
+}
+
+// lsh256 is LSH-256-n, with output `size` of up to 32 bytes
+type lsh256 struct {
+	size int
+	iv   [lshWords]uint32
+	cv   [lshWords]uint32
+	x    [lsh256Block]byte
+	nx   int
+}
+
+func newLSH256(size int) hash.Hash {
+	d := &lsh256{size: size}
+	// the IV is the compression of a zero block, with the word size and output size in bits
+	d.iv[0], d.iv[1] = 32, uint32(size*8)
+	var zero [lsh256Block]byte
+	d.cv = d.iv
+	d.block(zero[:])
+	d.iv = d.cv
+	d.Reset()
+	return d
+}
+
+func (d *lsh256) Reset() {
+	d.cv = d.iv
+	d.nx = 0
+}
+
+func (d *lsh256) Size() int      { return d.size }
+func (d *lsh256) BlockSize() int { return lsh256Block }
+
+func (d *lsh256) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// a full block is only compressed when more data follows, as the last block is padded
+		if d.nx == lsh256Block {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *lsh256) Sum(b []byte) []byte {
+	d0 := *d
+	if d0.nx == lsh256Block {
+		d0.block(d0.x[:])
+		d0.nx = 0
+	}
+	// padding is a one bit and zeros, filling the block
+	d0.x[d0.nx] = 0x80
+	for i := d0.nx + 1; i < lsh256Block; i++ {
+		d0.x[i] = 0
+	}
+	d0.block(d0.x[:])
+
+	var sum [lsh256MaxSize]byte
+	for l := 0; l < 8; l++ {
+		binary.LittleEndian.PutUint32(sum[l*4:], d0.cv[l]^d0.cv[l+8])
+	}
+	return append(b, sum[:d.size]...)
+}
+
+func (d *lsh256) block(p []byte) {
+	var m, prev [lshWords]uint32
+	for l := 0; l < lshWords; l++ {
+		prev[l] = binary.LittleEndian.Uint32(p[l*4:])
+		m[l] = binary.LittleEndian.Uint32(p[64+l*4:])
+	}
+	cv := d.cv
+	for j := 0; j < lsh256Steps; j++ {
+		// prev holds the message words of step j, and m those of step j+1
+		var t [lshWords]uint32
+		for l := range t {
+			t[l] = cv[l] ^ prev[l]
+		}
+		alpha, beta := 29, 1
+		if j%2 == 1 {
+			alpha, beta = 5, 17
+		}
+		for l := 0; l < 8; l++ {
+			x, y := t[l], t[l+8]
+			x = bits.RotateLeft32(x+y, alpha) ^ lsh256SC[j][l]
+			y = bits.RotateLeft32(x+y, beta)
+			x += y
+			y = bits.RotateLeft32(y, lsh256Gamma[l])
+			t[l], t[l+8] = x, y
+		}
+		for l := range cv {
+			cv[l] = t[lshSigma[l]]
+		}
+		var next [lshWords]uint32
+		for l := range next {
+			next[l] = m[l] + prev[lshTau[l]]
+		}
+		prev, m = m, next
+	}
+	for l := range cv {
+		d.cv[l] = cv[l] ^ prev[l]
+	}
+}
+
+// lsh512 is LSH-512-n, with output `size` of up to 64 bytes
+type lsh512 struct {
+	size int
+	iv   [lshWords]uint64
+	cv   [lshWords]uint64
+	x    [lsh512Block]byte
+	nx   int
+}
+
+func newLSH512(size int) hash.Hash {
+	d := &lsh512{size: size}
+	// the IV is the compression of a zero block, with the word size and output size in bits
+	d.iv[0], d.iv[1] = 64, uint64(size*8)
+	var zero [lsh512Block]byte
+	d.cv = d.iv
+	d.block(zero[:])
+	d.iv = d.cv
+	d.Reset()
+	return d
+}
+
+func (d *lsh512) Reset() {
+	d.cv = d.iv
+	d.nx = 0
+}
+
+func (d *lsh512) Size() int      { return d.size }
+func (d *lsh512) BlockSize() int { return lsh512Block }
+
+func (d *lsh512) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// a full block is only compressed when more data follows, as the last block is padded
+		if d.nx == lsh512Block {
+			d.block(d.x[:])
+			d.nx = 0
+		}
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *lsh512) Sum(b []byte) []byte {
+	d0 := *d
+	if d0.nx == lsh512Block {
+		d0.block(d0.x[:])
+		d0.nx = 0
+	}
+	// padding is a one bit and zeros, filling the block
+	d0.x[d0.nx] = 0x80
+	for i := d0.nx + 1; i < lsh512Block; i++ {
+		d0.x[i] = 0
+	}
+	d0.block(d0.x[:])
+
+	var sum [lsh512MaxSize]byte
+	for l := 0; l < 8; l++ {
+		binary.LittleEndian.PutUint64(sum[l*8:], d0.cv[l]^d0.cv[l+8])
+	}
+	return append(b, sum[:d.size]...)
+}
+
+func (d *lsh512) block(p []byte) {
+	var m, prev [lshWords]uint64
+	for l := 0; l < lshWords; l++ {
+		prev[l] = binary.LittleEndian.Uint64(p[l*8:])
+		m[l] = binary.LittleEndian.Uint64(p[128+l*8:])
+	}
+	cv := d.cv
+	for j := 0; j < lsh512Steps; j++ {
+		var t [lshWords]uint64
+		for l := range t {
+			t[l] = cv[l] ^ prev[l]
+		}
+		alpha, beta := 23, 59
+		if j%2 == 1 {
+			alpha, beta = 7, 3
+		}
+		for l := 0; l < 8; l++ {
+			x, y := t[l], t[l+8]
+			x = bits.RotateLeft64(x+y, alpha) ^ lsh512SC[j][l]
+			y = bits.RotateLeft64(x+y, beta)
+			x += y
+			y = bits.RotateLeft64(y, lsh512Gamma[l])
+			t[l], t[l+8] = x, y
+		}
+		for l := range cv {
+			cv[l] = t[lshSigma[l]]
+		}
+		var next [lshWords]uint64
+		for l := range next {
+			next[l] = m[l] + prev[lshTau[l]]
+		}
+		prev, m = m, next
+	}
+	for l := range cv {
+		d.cv[l] = cv[l] ^ prev[l]
+	}
+}
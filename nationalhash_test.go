@@ -0,0 +1,85 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNationalStandardHashes(t *testing.T) {
+	for _, tc := range []struct {
+		algo     string
+		input    string
+		expected string
+	}{
+		// GB/T 32905-2016 example 1
+		{"sm3", "abc", "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"},
+		// from the HAS-160 specification
+		{"has160", "a", "4872bcbc4cd0f0a9dc7c2f7045e5b43b6c830db8"},
+		{"has160", "abc", "975e810488cf2a3d49838478124afce4b1c78804"},
+		{"has160", "message digest", "2338dbc8638d31225f73086246ba529f96710bc6"},
+		{"has160", "abcdefghijklmnopqrstuvwxyz", "596185c9ab6703d0d0dbb98702bc0f5729cd1d3c"},
+		// from the KISA LSH test vectors
+		{"lsh-256-256", "abc", "5fbf365daea5446a7053c52b57404d77a07a5f48a1f7c1963a0898ba1b714741"},
+		{"lsh-512-512", "abc", "a3d93cfe60dc1aacdd3bd4bef0a6985381a396c7d49d9fd177795697c3535208b5c57224bef21084d42083e95a4bd8eb33e869812b65031c428819a1e7ce596d"},
+		// from the DSTU 7564:2014 examples, of the bytes 00, 01, 02 and so on
+		{"kupyna-256", kupynaExample(0), "cd5101d1ccdf0d1d1f4ada56e888cd724ca1a0838a3521e7131d4fb78d0f5eb6"},
+		{"kupyna-256", kupynaExample(95), "1075c8b0cb910f116bda5fa1f19c29cf8ecc75caff7208ba2994b68fc56e8d16"},
+		{"kupyna-256", kupynaExample(128), "0a9474e645a7d25e255e9e89fff42ec7eb31349007059284f0b182e452bda882"},
+		{"kupyna-256", kupynaExample(256), "d305a32b963d149dc765f68594505d4077024f836c1bf03806e1624ce176c08f"},
+		{"kupyna-512", kupynaExample(0), "656b2f4cd71462388b64a37043ea55dbe445d452aecd46c3298343314ef04019bcfa3f04265a9857f91be91fce197096187ceda78c9c1c021c294a0689198538"},
+		{"kupyna-512", kupynaExample(64), "3813e2109118cdfb5a6d5e72f7208dccc80a2dfb3afdfb02f46992b5edbe536b3560dd1d7e29c6f53978af58b444e37ba685c0dd910533ba5d78efffc13de62a"},
+	} {
+		calc := NewCalculator(strings.NewReader(tc.input))
+		sum, err := calc.Sum(tc.algo)
+		assert.Equal(t, nil, err, tc.algo)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo)
+	}
+}
+
+func TestNationalStandardHashesBlockBoundary(t *testing.T) {
+	// input of exactly one block is padded with an extra block
+	for _, algo := range []string{"has160", "lsh-256-256", "lsh-512-512", "kupyna-256", "kupyna-512"} {
+		h, err := NewHash(algo)
+		assert.Equal(t, nil, err)
+		data := []byte(strings.Repeat("a", h.BlockSize()))
+		_, _ = h.Write(data[:1])
+		_, _ = h.Write(data[1:])
+		split := h.Sum(nil)
+
+		calc := NewCalculator(strings.NewReader(string(data)))
+		sum, err := calc.Sum(algo)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, sum, split, algo)
+		assert.Equal(t, h.Size(), len(sum), algo)
+	}
+}
+
+func TestKupynaBlockBoundary(t *testing.T) {
+	// the DSTU 7564:2014 examples of exactly one block, which is padded with an extra block
+	for _, tc := range []struct {
+		algo     string
+		expected string
+	}{
+		{"kupyna-256", "08f4ee6f1be6903b324c4e27990cb24ef69dd58dbe84813ee0a52f6631239875"},
+		{"kupyna-512", "76ed1ac28b1d0143013ffa87213b4090b356441263c13e03fa060a8cada32b979635657f256b15d5fca4a174de029f0b1b4387c878fcc1c00e8705d783fd7ffe"},
+	} {
+		h, err := NewHash(tc.algo)
+		assert.Equal(t, nil, err)
+		data := []byte(kupynaExample(h.BlockSize()))
+		_, _ = h.Write(data[:1])
+		_, _ = h.Write(data[1:])
+		assert.Equal(t, tc.expected, hex.EncodeToString(h.Sum(nil)), tc.algo)
+	}
+}
+
+// kupynaExample returns the first n bytes of 00, 01, 02 and so on
+func kupynaExample(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return string(b)
+}
@@ -0,0 +1,415 @@
+package gohash
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// OCI image layouts hold blobs named by their digest, referenced by descriptors of a media type,
+// digest and size. See https://github.com/opencontainers/image-spec/blob/main/image-layout.md
+
+const (
+	// blobs up to this size are kept in memory, for manifests and configs to be parsed
+	imageMaxData = 4 * 1024 * 1024
+)
+
+var (
+	digestAlgorithmExpr = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*$`)
+	digestEncodedExpr   = regexp.MustCompile(`^[a-f0-9]+$`)
+)
+
+// ParseDigest returns the algorithm and checksum of a digest of the OCI image specification,
+// such as "sha256:<hex>". The algorithm is a registered hash, and the checksum lower case hex
+func ParseDigest(digest string) (string, []byte, error) {
+	i := strings.Index(digest, ":")
+	if i == -1 {
+		return "", nil, fmt.Errorf("invalid digest %q", digest)
+	}
+	algo, encoded := digest[:i], digest[i+1:]
+	if !digestAlgorithmExpr.MatchString(algo) || !digestEncodedExpr.MatchString(encoded) {
+		return "", nil, fmt.Errorf("invalid digest %q", digest)
+	}
+	h, err := NewHash(algo)
+	if err != nil {
+		return "", nil, err
+	}
+	sum, err := hex.DecodeString(encoded)
+	if err != nil || len(sum) != h.Size() {
+		return "", nil, fmt.Errorf("invalid digest %q", digest)
+	}
+	return algo, sum, nil
+}
+
+// the media types of image indexes and manifests
+var (
+	imageIndexTypes = map[string]bool{
+		"application/vnd.oci.image.index.v1+json":                   true,
+		"application/vnd.docker.distribution.manifest.list.v2+json": true,
+	}
+	imageManifestTypes = map[string]bool{
+		"application/vnd.oci.image.manifest.v1+json":           true,
+		"application/vnd.docker.distribution.manifest.v2+json": true,
+	}
+)
+
+// ImageNode is a descriptor of an image, with the descriptors it references
+type ImageNode struct {
+	MediaType string
+	Digest    string
+	// -1 if not known
+	Size int64
+	// the file in the image layout or archive, such as "blobs/sha256/<hex>"
+	Path string
+	// the reference name or tags of the image, if any
+	Ref string
+	// what is wrong with the blob, nil if it matches the descriptor
+	Err      error
+	Children []*ImageNode
+}
+
+// Failed returns true if the node or any of its children failed verification
+func (n *ImageNode) Failed() bool {
+	if n.Err != nil {
+		return true
+	}
+	for _, c := range n.Children {
+		if c.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// imageFile is a file of an image layout, with the digest of its contents
+type imageFile struct {
+	size   int64
+	digest string
+	// the contents, if at most imageMaxData bytes
+	data []byte
+}
+
+// capture keeps the first imageMaxData bytes written, and counts all bytes
+type capture struct {
+	data []byte
+	n    int64
+}
+
+func (c *capture) Write(p []byte) (int, error) {
+	if c.n+int64(len(p)) <= imageMaxData {
+		c.data = append(c.data, p...)
+	}
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// returns the algorithm of the digest of file `name`, from the "blobs/<algorithm>/" directory
+func imageFileAlgo(name string) string {
+	parts := strings.Split(name, "/")
+	if len(parts) == 3 && parts[0] == "blobs" {
+		if _, err := NewHash(parts[1]); err == nil {
+			return parts[1]
+		}
+	}
+	return "sha256"
+}
+
+func readImageFile(name string, r io.Reader) (*imageFile, error) {
+	c := &capture{}
+	digest, err := NewCalculator(io.TeeReader(r, c)).Digest(imageFileAlgo(name))
+	if err != nil {
+		return nil, err
+	}
+	f := &imageFile{size: c.n, digest: digest}
+	if c.n <= imageMaxData {
+		f.data = c.data
+	}
+	return f, nil
+}
+
+// imageLayout gives the files of an image layout directory or archive, by slash separated
+// name. Missing files are nil
+type imageLayout func(name string) (*imageFile, error)
+
+// returns the files of the image layout directory `dir`, which are read when first used
+func imageLayoutDir(dir string) imageLayout {
+	files := map[string]*imageFile{}
+	return func(name string) (*imageFile, error) {
+		if f, ok := files[name]; ok {
+			return f, nil
+		}
+		r, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		f, err := readImageFile(name, r)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = f
+		return f, nil
+	}
+}
+
+// returns the files of the tar archive `name`, such as from docker save, which may be gzip
+// compressed. All files are read in a single pass
+func imageLayoutTar(name string) (imageLayout, error) {
+	r, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	var tr *tar.Reader
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		tr = tar.NewReader(gz)
+	} else {
+		tr = tar.NewReader(br)
+	}
+
+	files := map[string]*imageFile{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		f, err := readImageFile(name, tr)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = f
+	}
+	return func(name string) (*imageFile, error) {
+		return files[name], nil
+	}, nil
+}
+
+// VerifyImage verifies the OCI image layout directory or docker save archive `name`. The
+// descriptors of index.json are verified with the manifests, configs and layers they
+// reference. Archives of docker before 25.0 without index.json are verified from
+// manifest.json, by the config file names and the layer diff ids of the configs
+func VerifyImage(name string) (*ImageNode, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	var layout imageLayout
+	if fi.IsDir() {
+		layout = imageLayoutDir(name)
+	} else if layout, err = imageLayoutTar(name); err != nil {
+		return nil, err
+	}
+
+	index, err := layout("index.json")
+	if err != nil {
+		return nil, err
+	}
+	if index != nil {
+		root := &ImageNode{Path: "index.json", Size: index.size}
+		return root, verifyImageIndex(layout, root, index)
+	}
+	manifest, err := layout("manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		root := &ImageNode{Path: "manifest.json", Size: manifest.size}
+		return root, verifyDockerManifest(layout, root, manifest)
+	}
+	return nil, fmt.Errorf("%s has no index.json or manifest.json", name)
+}
+
+// imageDescriptor is a descriptor of the OCI image specification
+type imageDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	URLs        []string          `json:"urls"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// the fields of image indexes and manifests
+type imageManifest struct {
+	MediaType string            `json:"mediaType"`
+	Manifests []imageDescriptor `json:"manifests"`
+	Config    *imageDescriptor  `json:"config"`
+	Layers    []imageDescriptor `json:"layers"`
+}
+
+// returns the parsed index or manifest `f`
+func parseImageManifest(f *imageFile) (*imageManifest, error) {
+	if f.data == nil {
+		return nil, fmt.Errorf("manifest is larger than %d bytes", imageMaxData)
+	}
+	var m imageManifest
+	if err := json.Unmarshal(f.data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	return &m, nil
+}
+
+func verifyImageIndex(layout imageLayout, node *ImageNode, f *imageFile) error {
+	m, err := parseImageManifest(f)
+	if err != nil {
+		node.Err = err
+		return nil
+	}
+	for _, d := range m.Manifests {
+		child, err := verifyImageDescriptor(layout, d)
+		if err != nil {
+			return err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return nil
+}
+
+// verifies the blob of descriptor `d`, and the descriptors of indexes and manifests
+func verifyImageDescriptor(layout imageLayout, d imageDescriptor) (*ImageNode, error) {
+	node := &ImageNode{MediaType: d.MediaType, Digest: d.Digest, Size: d.Size, Ref: d.Annotations["org.opencontainers.image.ref.name"]}
+	algo, sum, err := ParseDigest(d.Digest)
+	if err != nil {
+		node.Err = err
+		return node, nil
+	}
+	node.Path = "blobs/" + algo + "/" + hex.EncodeToString(sum)
+	f, err := layout(node.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case f == nil && len(d.URLs) > 0:
+		// non-distributable layers are not stored
+		return node, nil
+	case f == nil:
+		node.Err = fmt.Errorf("missing blob")
+		return node, nil
+	case f.digest != d.Digest:
+		node.Err = fmt.Errorf("digest mismatch, is %s", f.digest)
+		return node, nil
+	case f.size != d.Size:
+		node.Err = fmt.Errorf("size mismatch, is %d", f.size)
+		return node, nil
+	}
+
+	switch {
+	case imageIndexTypes[d.MediaType]:
+		return node, verifyImageIndex(layout, node, f)
+	case imageManifestTypes[d.MediaType]:
+		m, err := parseImageManifest(f)
+		if err != nil {
+			node.Err = err
+			return node, nil
+		}
+		if m.Config == nil {
+			node.Err = fmt.Errorf("manifest has no config")
+			return node, nil
+		}
+		for _, d := range append([]imageDescriptor{*m.Config}, m.Layers...) {
+			child, err := verifyImageDescriptor(layout, d)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node, nil
+}
+
+// verifies manifest.json of docker save, whose configs are named by their sha256, and
+// whose layers are given by the uncompressed sha256 in rootfs.diff_ids of the config
+func verifyDockerManifest(layout imageLayout, root *ImageNode, f *imageFile) error {
+	var images []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}
+	if f.data == nil || json.Unmarshal(f.data, &images) != nil {
+		root.Err = fmt.Errorf("invalid manifest.json")
+		return nil
+	}
+	for _, image := range images {
+		node := &ImageNode{
+			MediaType: "application/vnd.docker.container.image.v1+json",
+			Path:      image.Config,
+			Ref:       strings.Join(image.RepoTags, ", "),
+			Size:      -1,
+		}
+		root.Children = append(root.Children, node)
+		// the config is named "<hex>.json", or "blobs/sha256/<hex>" since docker 25.0
+		node.Digest = "sha256:" + strings.TrimSuffix(path.Base(image.Config), ".json")
+		config, err := layout(image.Config)
+		if err != nil {
+			return err
+		}
+		switch {
+		case config == nil:
+			node.Err = fmt.Errorf("missing config")
+			continue
+		case config.digest != node.Digest:
+			node.Err = fmt.Errorf("digest mismatch, is %s", config.digest)
+			continue
+		case config.data == nil:
+			node.Err = fmt.Errorf("config is larger than %d bytes", imageMaxData)
+			continue
+		}
+		node.Size = config.size
+
+		var c struct {
+			RootFS struct {
+				DiffIDs []string `json:"diff_ids"`
+			} `json:"rootfs"`
+		}
+		if err := json.Unmarshal(config.data, &c); err != nil {
+			node.Err = fmt.Errorf("invalid config: %v", err)
+			continue
+		}
+		if len(c.RootFS.DiffIDs) != len(image.Layers) {
+			node.Err = fmt.Errorf("config has %d diff ids for %d layers", len(c.RootFS.DiffIDs), len(image.Layers))
+			continue
+		}
+		for i, name := range image.Layers {
+			layer := &ImageNode{MediaType: "application/vnd.docker.image.rootfs.diff.tar", Digest: c.RootFS.DiffIDs[i], Path: name, Size: -1}
+			node.Children = append(node.Children, layer)
+			f, err := layout(name)
+			if err != nil {
+				return err
+			}
+			switch {
+			case f == nil:
+				layer.Err = fmt.Errorf("missing layer")
+			case f.digest != layer.Digest:
+				layer.Err = fmt.Errorf("digest mismatch, is %s", f.digest)
+			default:
+				layer.Size = f.size
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package gohash
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDigest(t *testing.T) {
+	sum := sha256Sum([]byte(fox))
+	algo, res, err := ParseDigest("sha256:" + hex.EncodeToString(sum))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha256", algo)
+	assert.Equal(t, sum, res)
+
+	for _, digest := range []string{
+		"",
+		"sha256",
+		"sha256:" + strings.ToUpper(hex.EncodeToString(sum)),
+		"sha256:" + hex.EncodeToString(sum[:31]),
+		"sha512:" + hex.EncodeToString(sum),
+		"SHA256:" + hex.EncodeToString(sum),
+		"nope:" + hex.EncodeToString(sum),
+	} {
+		_, _, err := ParseDigest(digest)
+		assert.NotEqual(t, nil, err, digest)
+	}
+}
+
+// an image layout written by writeTestImage
+type testImage struct {
+	dir                       string
+	index, manifest, config   string
+	layer1, layer2, manifest2 string
+}
+
+func digestOf(data []byte) string {
+	return "sha256:" + hex.EncodeToString(sha256Sum(data))
+}
+
+// writes the blob `data` to image layout `dir` and returns its descriptor
+func writeTestBlob(t *testing.T, dir, mediaType string, data []byte) map[string]interface{} {
+	digest := digestOf(data)
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(digest, "sha256:")), data, 0644))
+	return map[string]interface{}{"mediaType": mediaType, "digest": digest, "size": len(data)}
+}
+
+func mustJSON(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// writes an image layout with an index referencing a manifest, and a nested index
+// referencing a second manifest with the same config and a sha512 layer
+func writeTestImage(t *testing.T) testImage {
+	dir := t.TempDir()
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755))
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, "blobs", "sha512"), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644))
+
+	config := writeTestBlob(t, dir, "application/vnd.oci.image.config.v1+json", []byte(`{"architecture":"amd64","os":"linux"}`))
+	layer1 := writeTestBlob(t, dir, "application/vnd.oci.image.layer.v1.tar+gzip", bytes.Repeat([]byte(fox), 100))
+	manifest := writeTestBlob(t, dir, "application/vnd.oci.image.manifest.v1+json", mustJSON(map[string]interface{}{
+		"schemaVersion": 2, "mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"config": config, "layers": []interface{}{layer1},
+	}))
+	manifest["annotations"] = map[string]string{"org.opencontainers.image.ref.name": "latest"}
+
+	layer2Data := []byte(fox)
+	layer2Sum, _ := NewCalculator(bytes.NewReader(layer2Data)).Sum("sha512")
+	layer2 := map[string]interface{}{"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": fmt.Sprintf("sha512:%x", layer2Sum), "size": len(layer2Data)}
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "blobs", "sha512", hex.EncodeToString(layer2Sum)), layer2Data, 0644))
+	manifest2 := writeTestBlob(t, dir, "application/vnd.oci.image.manifest.v1+json", mustJSON(map[string]interface{}{
+		"schemaVersion": 2, "config": config, "layers": []interface{}{layer2},
+	}))
+	nested := writeTestBlob(t, dir, "application/vnd.oci.image.index.v1+json", mustJSON(map[string]interface{}{
+		"schemaVersion": 2, "manifests": []interface{}{manifest2},
+	}))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "index.json"), mustJSON(map[string]interface{}{
+		"schemaVersion": 2, "manifests": []interface{}{manifest, nested},
+	}), 0644))
+
+	return testImage{
+		dir:       dir,
+		index:     nested["digest"].(string),
+		manifest:  manifest["digest"].(string),
+		config:    config["digest"].(string),
+		layer1:    layer1["digest"].(string),
+		layer2:    layer2["digest"].(string),
+		manifest2: manifest2["digest"].(string),
+	}
+}
+
+// returns the digests and errors of the tree at `node`, indented by depth
+func imageTree(node *ImageNode, depth int) []string {
+	line := strings.Repeat("  ", depth) + node.Path
+	if node.Err != nil {
+		line += " " + node.Err.Error()
+	}
+	res := []string{line}
+	for _, c := range node.Children {
+		res = append(res, imageTree(c, depth+1)...)
+	}
+	return res
+}
+
+func blobPath(digest string) string {
+	return "blobs/" + strings.Replace(digest, ":", "/", 1)
+}
+
+func TestVerifyImage(t *testing.T) {
+	img := writeTestImage(t)
+	root, err := VerifyImage(img.dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, root.Failed())
+	assert.Equal(t, []string{
+		"index.json",
+		"  " + blobPath(img.manifest),
+		"    " + blobPath(img.config),
+		"    " + blobPath(img.layer1),
+		"  " + blobPath(img.index),
+		"    " + blobPath(img.manifest2),
+		"      " + blobPath(img.config),
+		"      " + blobPath(img.layer2),
+	}, imageTree(root, 0))
+	assert.Equal(t, "latest", root.Children[0].Ref)
+	assert.Equal(t, int64(len(fox)*100), root.Children[0].Children[1].Size)
+
+	// a changed layer and a missing layer
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(img.dir, filepath.FromSlash(blobPath(img.layer1))), []byte(fox), 0644))
+	assert.Equal(t, nil, os.Remove(filepath.Join(img.dir, filepath.FromSlash(blobPath(img.layer2)))))
+	root, err = VerifyImage(img.dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, root.Failed())
+	assert.Equal(t, []string{
+		"index.json",
+		"  " + blobPath(img.manifest),
+		"    " + blobPath(img.config),
+		"    " + blobPath(img.layer1) + " digest mismatch, is " + digestOf([]byte(fox)),
+		"  " + blobPath(img.index),
+		"    " + blobPath(img.manifest2),
+		"      " + blobPath(img.config),
+		"      " + blobPath(img.layer2) + " missing blob",
+	}, imageTree(root, 0))
+
+	_, err = VerifyImage(t.TempDir())
+	assert.NotEqual(t, nil, err)
+}
+
+// writes the files of `dir` to a tar archive, gzip compressed if `compress`
+func writeTestTar(t *testing.T, dir string, compress bool) string {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.Mode().IsRegular() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: "./" + filepath.ToSlash(rel), Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, tw.Close())
+
+	data := buf.Bytes()
+	if compress {
+		var gz bytes.Buffer
+		w := gzip.NewWriter(&gz)
+		_, _ = w.Write(data)
+		assert.Equal(t, nil, w.Close())
+		data = gz.Bytes()
+	}
+	name := filepath.Join(t.TempDir(), "image.tar")
+	assert.Equal(t, nil, ioutil.WriteFile(name, data, 0644))
+	return name
+}
+
+func TestVerifyImageTar(t *testing.T) {
+	img := writeTestImage(t)
+	for _, compress := range []bool{false, true} {
+		root, err := VerifyImage(writeTestTar(t, img.dir, compress))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, false, root.Failed())
+		assert.Equal(t, 8, len(imageTree(root, 0)))
+	}
+
+	// a blob of another size than its descriptor
+	manifest := filepath.Join(img.dir, filepath.FromSlash(blobPath(img.manifest)))
+	data, err := ioutil.ReadFile(manifest)
+	assert.Equal(t, nil, err)
+	index, err := ioutil.ReadFile(filepath.Join(img.dir, "index.json"))
+	assert.Equal(t, nil, err)
+	index = bytes.Replace(index, []byte(fmt.Sprintf(`"size":%d`, len(data))), []byte(fmt.Sprintf(`"size":%d`, len(data)+1)), 1)
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(img.dir, "index.json"), index, 0644))
+	root, err := VerifyImage(writeTestTar(t, img.dir, false))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, fmt.Sprintf("size mismatch, is %d", len(data)), root.Children[0].Err.Error())
+}
+
+func TestVerifyImageDockerManifest(t *testing.T) {
+	// docker save before 25.0
+	dir := t.TempDir()
+	layer := bytes.Repeat([]byte(fox), 10)
+	layerID := hex.EncodeToString(sha256Sum([]byte("layer id")))
+	config := mustJSON(map[string]interface{}{"rootfs": map[string]interface{}{"type": "layers", "diff_ids": []string{digestOf(layer)}}})
+	configName := hex.EncodeToString(sha256Sum(config)) + ".json"
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, layerID), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, layerID, "layer.tar"), layer, 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, configName), config, 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "manifest.json"), mustJSON([]interface{}{
+		map[string]interface{}{"Config": configName, "RepoTags": []string{"fox:latest"}, "Layers": []string{layerID + "/layer.tar"}},
+	}), 0644))
+
+	root, err := VerifyImage(writeTestTar(t, dir, false))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, root.Failed())
+	assert.Equal(t, []string{"manifest.json", "  " + configName, "    " + layerID + "/layer.tar"}, imageTree(root, 0))
+	assert.Equal(t, "fox:latest", root.Children[0].Ref)
+	assert.Equal(t, digestOf(layer), root.Children[0].Children[0].Digest)
+
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, layerID, "layer.tar"), layer[1:], 0644))
+	root, err = VerifyImage(writeTestTar(t, dir, false))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, root.Failed())
+	assert.Equal(t, "digest mismatch, is "+digestOf(layer[1:]), root.Children[0].Children[0].Err.Error())
+}
@@ -0,0 +1,289 @@
+package gohash
+
+import (
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cxmcc/tiger"
+	"golang.org/x/crypto/md4"
+)
+
+// hashes of peer-to-peer networks, as calculated by rhash
+
+const (
+	// the ed2k chunk, and the AICH part size
+	ed2kChunkSize = 9728000
+	// the size of AICH blocks, each part has 53 blocks with the last being smaller
+	aichBlockSize     = 184320
+	aichBlocksPerPart = (ed2kChunkSize + aichBlockSize - 1) / aichBlockSize
+	tthLeafSize       = 1024
+)
+
+// ed2k is the eDonkey2000 hash, the md4 of the md4 of each 9500 KiB chunk. Input of
+// a single chunk gives its md4. Input which is an exact multiple of the chunk size has
+// no additional empty chunk, as calculated by eMule 0.50 and later
+type ed2k struct {
+	chunk hash.Hash
+	n     int
+	// the md4 of the previous chunks
+	chunks []byte
+}
+
+func newED2K() hash.Hash {
+	return &ed2k{chunk: md4.New()}
+}
+
+func (d *ed2k) Reset() {
+	d.chunk.Reset()
+	d.n = 0
+	d.chunks = nil
+}
+
+func (d *ed2k) Size() int      { return md4.Size }
+func (d *ed2k) BlockSize() int { return md4.BlockSize }
+
+func (d *ed2k) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// a full chunk is only added when more data follows
+		if d.n == ed2kChunkSize {
+			d.chunks = d.chunk.Sum(d.chunks)
+			d.chunk.Reset()
+			d.n = 0
+		}
+		c := len(p)
+		if c > ed2kChunkSize-d.n {
+			c = ed2kChunkSize - d.n
+		}
+		_, _ = d.chunk.Write(p[:c])
+		d.n += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *ed2k) Sum(b []byte) []byte {
+	if d.chunks == nil {
+		return d.chunk.Sum(b)
+	}
+	h := md4.New()
+	_, _ = h.Write(d.chunks)
+	_, _ = h.Write(d.chunk.Sum(nil))
+	return h.Sum(b)
+}
+
+// aich is the Advanced Intelligent Corruption Handling hash of eMule, a sha1 tree over
+// blocks of 180 KiB within each 9500 KiB part
+type aich struct {
+	block  hash.Hash
+	n      int
+	length uint64
+	// the sha1 of the previous blocks
+	blocks []byte
+}
+
+func newAICH() hash.Hash {
+	return &aich{block: sha1.New()}
+}
+
+func (d *aich) Reset() {
+	d.block.Reset()
+	d.n = 0
+	d.length = 0
+	d.blocks = nil
+}
+
+func (d *aich) Size() int      { return sha1.Size }
+func (d *aich) BlockSize() int { return sha1.BlockSize }
+
+// returns the size of the block at `pos`, the last block of a part is smaller
+func aichBlockSizeAt(pos uint64) int {
+	if left := ed2kChunkSize - pos%ed2kChunkSize; left < aichBlockSize {
+		return int(left)
+	}
+	return aichBlockSize
+}
+
+func (d *aich) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := aichBlockSizeAt(d.length - uint64(d.n))
+		c := len(p)
+		if c > size-d.n {
+			c = size - d.n
+		}
+		_, _ = d.block.Write(p[:c])
+		d.n += c
+		d.length += uint64(c)
+		p = p[c:]
+		if d.n == size {
+			d.blocks = d.block.Sum(d.blocks)
+			d.block.Reset()
+			d.n = 0
+		}
+	}
+	return n, nil
+}
+
+func (d *aich) Sum(b []byte) []byte {
+	blocks := d.blocks
+	if d.n > 0 || d.length == 0 {
+		blocks = d.block.Sum(blocks[:len(blocks):len(blocks)])
+	}
+	return append(b, aichTree(blocks, 0, d.length, true)...)
+}
+
+// returns the hash of the tree node over `size` bytes at `start`. As in eMule, nodes are
+// split into parts while larger than a part, and into blocks otherwise. A left branch
+// has the larger half if the number of parts or blocks is odd, a right branch the smaller
+func aichTree(blocks []byte, start, size uint64, left bool) []byte {
+	if size <= aichBlockSize {
+		i := (start/ed2kChunkSize)*aichBlocksPerPart + (start%ed2kChunkSize)/aichBlockSize
+		return blocks[i*sha1.Size : (i+1)*sha1.Size]
+	}
+	base := uint64(aichBlockSize)
+	if size > ed2kChunkSize {
+		base = ed2kChunkSize
+	}
+	count := (size + base - 1) / base
+	if left {
+		count++
+	}
+	leftSize := count / 2 * base
+
+	h := sha1.New()
+	_, _ = h.Write(aichTree(blocks, start, leftSize, true))
+	_, _ = h.Write(aichTree(blocks, start+leftSize, size-leftSize, false))
+	return h.Sum(nil)
+}
+
+// tth is the Tiger Tree Hash of the THEX format, a tiger192 tree over leaves of 1024 bytes,
+// where leaves are prefixed with 0x00 and inner nodes with 0x01
+type tth struct {
+	leaf [tthLeafSize]byte
+	n    int
+	// the subtrees not yet joined, of decreasing size
+	stack []tthNode
+}
+
+type tthNode struct {
+	leaves uint64
+	sum    []byte
+}
+
+func newTTH() hash.Hash {
+	return &tth{}
+}
+
+func (d *tth) Reset() {
+	d.n = 0
+	d.stack = nil
+}
+
+func (d *tth) Size() int      { return tiger.Size }
+func (d *tth) BlockSize() int { return tthLeafSize }
+
+func tthHash(prefix byte, data ...[]byte) []byte {
+	h := tiger.New()
+	_, _ = h.Write([]byte{prefix})
+	for _, b := range data {
+		_, _ = h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+func (d *tth) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// a full leaf is only added when more data follows, so empty input has one empty leaf
+		if d.n == tthLeafSize {
+			d.addLeaf()
+		}
+		c := copy(d.leaf[d.n:], p)
+		d.n += c
+		p = p[c:]
+	}
+	return n, nil
+}
+
+func (d *tth) addLeaf() {
+	node := tthNode{leaves: 1, sum: tthHash(0x00, d.leaf[:d.n])}
+	// join subtrees of equal size
+	for len(d.stack) > 0 && d.stack[len(d.stack)-1].leaves == node.leaves {
+		top := d.stack[len(d.stack)-1]
+		d.stack = d.stack[:len(d.stack)-1]
+		node = tthNode{leaves: top.leaves * 2, sum: tthHash(0x01, top.sum, node.sum)}
+	}
+	d.stack = append(d.stack, node)
+	d.n = 0
+}
+
+func (d *tth) Sum(b []byte) []byte {
+	d0 := *d
+	d0.stack = append([]tthNode(nil), d.stack...)
+	d0.addLeaf()
+
+	// a last node without a sibling is promoted to the level above, so the remaining
+	// subtrees are joined from the smallest
+	sum := d0.stack[len(d0.stack)-1].sum
+	for i := len(d0.stack) - 2; i >= 0; i-- {
+		sum = tthHash(0x01, d0.stack[i].sum, sum)
+	}
+	return append(b, sum...)
+}
+
+var p2pHashes = []HashAlgorithm{
+	{Name: "ed2k", Aliases: []string{"edonkey"}, Bits: 128, Family: "p2p", New: newED2K},
+	{Name: "aich", Bits: 160, Family: "p2p", New: newAICH},
+	{Name: "tth", Aliases: []string{"tiger-tree"}, Bits: 192, Family: "p2p", New: newTTH},
+}
+
+func init() {
+	for _, algo := range p2pHashes {
+		mustRegisterHash(algo)
+	}
+}
+
+// the magnet link urn of each algorithm, and its encoding
+var magnetURNs = map[string]struct {
+	urn    string
+	base32 bool
+}{
+	"ed2k": {"ed2k", false},
+	"aich": {"aich", true},
+	"tth":  {"tree:tiger", true},
+	"sha1": {"sha1", true},
+	"md5":  {"md5", false},
+}
+
+// MagnetLink returns a magnet uri for a file `name` of `size` bytes, with an exact topic
+// for each of `algos` ("ed2k", "aich", "tth", "sha1" or "md5"), taken from `hashes` as
+// returned by SumMany. `name` is left out if empty
+func MagnetLink(name string, size uint64, algos []string, hashes map[string][]byte) (string, error) {
+	params := []string{"xl=" + strconv.FormatUint(size, 10)}
+	if name != "" {
+		params = append(params, "dn="+strings.Replace(url.QueryEscape(name), "+", "%20", -1))
+	}
+	for _, algo := range algos {
+		m, ok := magnetURNs[resolveAlgoAliases(algo)]
+		if !ok {
+			return "", fmt.Errorf("no magnet link urn for %s", algo)
+		}
+		sum, ok := hashes[algo]
+		if !ok {
+			return "", fmt.Errorf("missing hash for %s", algo)
+		}
+		enc := hex.EncodeToString(sum)
+		if m.base32 {
+			enc = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum)
+		}
+		params = append(params, "xt=urn:"+m.urn+":"+enc)
+	}
+	return "magnet:?" + strings.Join(params, "&"), nil
+}
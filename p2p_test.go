@@ -0,0 +1,132 @@
+package gohash
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/md4"
+)
+
+func TestTigerTreeHash(t *testing.T) {
+	// from the THEX specification
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"", "LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"},
+		{"\x00", "VK54ZIEEVTWNAUI5D5RDFIL37LX2IQNSTAXFKSA"},
+		{strings.Repeat("A", 1024), "L66Q4YVNAFWVS23X2HJIRA5ZJ7WXR3F26RSASFA"},
+		{strings.Repeat("A", 1025), "PZMRYHGY6LTBEH63ZWAHDORHSYTLO4LEFUIKHWY"},
+	} {
+		calc := NewCalculator(strings.NewReader(tc.input))
+		sum, err := calc.Sum("tth")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.expected, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum), len(tc.input))
+	}
+}
+
+func md4Sum(data ...[]byte) []byte {
+	h := md4.New()
+	for _, b := range data {
+		_, _ = h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+func sha1Sum(data ...[]byte) []byte {
+	h := sha1.New()
+	for _, b := range data {
+		_, _ = h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+func TestED2K(t *testing.T) {
+	data := bytes.Repeat([]byte(fox), 2*ed2kChunkSize/len(fox)+1)
+	chunk1, chunk2 := data[:ed2kChunkSize], data[ed2kChunkSize:]
+
+	for _, tc := range []struct {
+		input    []byte
+		expected []byte
+	}{
+		{chunk1[:100], md4Sum(chunk1[:100])},
+		// an exact chunk has no additional empty chunk
+		{chunk1, md4Sum(chunk1)},
+		{data, md4Sum(md4Sum(chunk1), md4Sum(chunk2[:ed2kChunkSize]), md4Sum(chunk2[ed2kChunkSize:]))},
+	} {
+		calc := NewCalculator(bytes.NewReader(tc.input))
+		sum, err := calc.Sum("ed2k")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, hex.EncodeToString(tc.expected), hex.EncodeToString(sum), len(tc.input))
+	}
+}
+
+func TestAICH(t *testing.T) {
+	data := bytes.Repeat([]byte(fox), 3*aichBlockSize/len(fox))
+	b0, b1, b2 := data[:aichBlockSize], data[aichBlockSize:2*aichBlockSize], data[2*aichBlockSize:]
+
+	for _, tc := range []struct {
+		input    []byte
+		expected []byte
+	}{
+		{nil, sha1Sum()},
+		{b0, sha1Sum(b0)},
+		{data[:aichBlockSize+1], sha1Sum(sha1Sum(b0), sha1Sum(b1[:1]))},
+		// the root is a left branch, with the larger half on the left
+		{data, sha1Sum(sha1Sum(sha1Sum(b0), sha1Sum(b1)), sha1Sum(b2))},
+	} {
+		calc := NewCalculator(bytes.NewReader(tc.input))
+		sum, err := calc.Sum("aich")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, hex.EncodeToString(tc.expected), hex.EncodeToString(sum), len(tc.input))
+	}
+
+	// the last block of each part is smaller
+	assert.Equal(t, aichBlockSize, aichBlockSizeAt(0))
+	assert.Equal(t, ed2kChunkSize-52*aichBlockSize, aichBlockSizeAt(52*aichBlockSize))
+	assert.Equal(t, aichBlockSize, aichBlockSizeAt(ed2kChunkSize))
+}
+
+func TestP2PHashesWriteSizes(t *testing.T) {
+	data := bytes.Repeat([]byte(fox), ed2kChunkSize/len(fox)+1000)
+	for _, algo := range []string{"ed2k", "aich", "tth"} {
+		h, _ := NewHash(algo)
+		_, _ = h.Write(data)
+		expected := h.Sum(nil)
+
+		h.Reset()
+		for p := data; len(p) > 0; {
+			n := 1000 + len(p)%7919
+			if n > len(p) {
+				n = len(p)
+			}
+			_, _ = h.Write(p[:n])
+			p = p[n:]
+		}
+		assert.Equal(t, expected, h.Sum(nil), algo)
+	}
+}
+
+func TestMagnetLink(t *testing.T) {
+	algos := []string{"ed2k", "aich", "tth"}
+	calc := NewCalculator(strings.NewReader(""))
+	hashes, err := calc.SumMany(algos)
+	assert.Equal(t, nil, err)
+
+	link, err := MagnetLink("empty file.txt", 0, algos, hashes)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "magnet:?xl=0&dn=empty%20file.txt"+
+		"&xt=urn:ed2k:31d6cfe0d16ae931b73c59d7e0c089c0"+
+		"&xt=urn:aich:3I42H3S6NNFQ2MSVX7XZKYAYSCX5QBYJ"+
+		"&xt=urn:tree:tiger:LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ", link)
+
+	_, err = MagnetLink("", 0, []string{"sha256"}, hashes)
+	assert.NotEqual(t, nil, err)
+	_, err = MagnetLink("", 0, []string{"sha1"}, hashes)
+	assert.NotEqual(t, nil, err)
+}
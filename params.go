@@ -0,0 +1,150 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HashParams are the parameters of an algorithm id such as "blake2b:output=20,salt=00ff".
+// Binary values such as keys and salts are hex encoded
+type HashParams map[string]string
+
+// parseAlgoSpec splits "name:key=value,key=value" into name and parameters
+func parseAlgoSpec(spec string) (string, HashParams, error) {
+	params := HashParams{}
+	pos := strings.Index(spec, ":")
+	if pos == -1 {
+		return spec, params, nil
+	}
+	name := spec[:pos]
+	for _, part := range strings.Split(spec[pos+1:], ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return "", nil, fmt.Errorf("%s: malformed parameter '%s', expected key=value", name, part)
+		}
+		key := strings.ToLower(kv[0])
+		if _, ok := params[key]; ok {
+			return "", nil, fmt.Errorf("%s: parameter %s given twice", name, key)
+		}
+		params[key] = kv[1]
+	}
+	return name, params, nil
+}
+
+// SplitAlgoList splits a comma separated list of algorithms such as
+// "md5,blake2b:output=20,salt=00ff,sha1", keeping parameters with their algorithm
+func SplitAlgoList(s string) []string {
+	res := []string{}
+	for _, part := range strings.Split(s, ",") {
+		// algorithm names never contain "=", so this is a parameter of the previous algorithm
+		if len(res) > 0 && strings.Contains(part, "=") && !strings.Contains(strings.SplitN(part, "=", 2)[0], ":") {
+			res[len(res)-1] += "," + part
+			continue
+		}
+		res = append(res, part)
+	}
+	return res
+}
+
+// String returns the parameters in "key=value,key=value" form, sorted by key
+func (p HashParams) String() string {
+	keys := []string{}
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		parts = append(parts, key+"="+p[key])
+	}
+	return strings.Join(parts, ",")
+}
+
+// returns an error if any parameter is not in `allowed`
+func (p HashParams) allow(allowed ...string) error {
+	for key := range p {
+		if !isStringInSlice(key, allowed) {
+			return fmt.Errorf("unknown parameter %s, allowed are %s", key, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// returns the hex encoded parameter, or nil if unset
+func (p HashParams) bytes(key string) ([]byte, error) {
+	s, ok := p[key]
+	if !ok {
+		return nil, nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s must be hex encoded: %v", key, err)
+	}
+	return b, nil
+}
+
+// returns the integer parameter, or `def` if unset
+func (p HashParams) int(key string, def int) (int, error) {
+	s, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %s must be an integer: %v", key, err)
+	}
+	return i, nil
+}
+
+// returns the unsigned integer parameter of `bitSize` bits, in decimal or in hex
+// with a "0x" prefix, or `def` if unset
+func (p HashParams) uint(key string, def uint64, bitSize int) (uint64, error) {
+	s, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	base := 10
+	if strings.HasPrefix(s, "0x") {
+		s, base = s[2:], 16
+	}
+	i, err := strconv.ParseUint(s, base, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %s must be a %d bit unsigned integer: %v", key, bitSize, err)
+	}
+	return i, nil
+}
+
+// maxOutputSize is the largest digest size in bytes of the "output" parameter
+const maxOutputSize = 1 << 20
+
+// returns the "output" parameter, the digest size in bytes, or `def` if unset
+func (p HashParams) output(def int) (int, error) {
+	output, err := p.int("output", def)
+	if err != nil {
+		return 0, err
+	}
+	if output < 1 || output > maxOutputSize {
+		return 0, fmt.Errorf("invalid output size %d", output)
+	}
+	return output, nil
+}
+
+// withOutputSize returns `algo` with the "output" parameter set to `size` bytes,
+// if the algorithm has a variable digest size and no output is given
+func withOutputSize(algo string, size int) (string, error) {
+	name, params, err := parseAlgoSpec(algo)
+	if err != nil {
+		return "", err
+	}
+	if info, ok := lookupHash(name); ok && info.VariableSize {
+		if _, ok := params["output"]; !ok && size > 0 {
+			params["output"] = strconv.Itoa(size)
+			return name + ":" + params.String(), nil
+		}
+	}
+	return algo, nil
+}
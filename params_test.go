@@ -0,0 +1,33 @@
+package gohash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAlgoSpec(t *testing.T) {
+	name, params, err := parseAlgoSpec("sha1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "sha1", name)
+	assert.Equal(t, 0, len(params))
+
+	name, params, err = parseAlgoSpec("blake3:Output=64,context=app 2020-01-01 12:00:00 v1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "blake3", name)
+	assert.Equal(t, HashParams{"output": "64", "context": "app 2020-01-01 12:00:00 v1"}, params)
+	assert.Equal(t, "context=app 2020-01-01 12:00:00 v1,output=64", params.String())
+
+	for _, spec := range []string{"blake3:", "blake3:output", "blake3:=1", "blake3:output=1,output=2"} {
+		_, _, err = parseAlgoSpec(spec)
+		assert.NotEqual(t, nil, err, spec)
+	}
+}
+
+func TestSplitAlgoList(t *testing.T) {
+	assert.Equal(t, []string{"md5"}, SplitAlgoList("md5"))
+	assert.Equal(t, []string{"md5", "sha1"}, SplitAlgoList("md5,sha1"))
+	assert.Equal(t,
+		[]string{"md5", "blake2b:output=20,salt=00ff", "blake3:output=8", "sha1"},
+		SplitAlgoList("md5,blake2b:output=20,salt=00ff,blake3:output=8,sha1"))
+}
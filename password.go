@@ -0,0 +1,269 @@
+package gohash
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/blowfish"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// password hashing functions, taking a salt and cost parameters. Without a salt
+// parameter an empty salt is used, or an all zero salt for bcrypt
+
+// bufferedHashLimit is the largest input of a bufferedHash
+const bufferedHashLimit = 64 << 20
+
+// bufferedHash is a hash.Hash for functions which can only be calculated over the
+// complete input, such as password hashes. As Write never returns an error, input of
+// more than bufferedHashLimit bytes is dropped and reported by Err, and Sum panics
+type bufferedHash struct {
+	data   []byte
+	err    error
+	size   int
+	derive func(data []byte) []byte
+}
+
+func newBufferedHash(size int, derive func(data []byte) []byte) *bufferedHash {
+	return &bufferedHash{size: size, derive: derive}
+}
+
+func (h *bufferedHash) Write(p []byte) (int, error) {
+	if h.err == nil && len(h.data)+len(p) > bufferedHashLimit {
+		h.data = nil
+		h.err = fmt.Errorf("input is larger than %d MiB, the limit of one-shot functions", bufferedHashLimit>>20)
+	}
+	if h.err == nil {
+		h.data = append(h.data, p...)
+	}
+	return len(p), nil
+}
+
+// Err returns the error of too large input, until Reset
+func (h *bufferedHash) Err() error { return h.err }
+
+func (h *bufferedHash) Sum(b []byte) []byte {
+	if h.err != nil {
+		panic(h.err)
+	}
+	return append(b, h.derive(h.data)...)
+}
+
+func (h *bufferedHash) Reset() {
+	h.data = h.data[:0]
+	h.err = nil
+}
+
+func (h *bufferedHash) Size() int { return h.size }
+
+// BlockSize returns 1, so HMAC is not supported
+func (h *bufferedHash) BlockSize() int { return 1 }
+
+const (
+	bcryptSaltSize    = 16
+	bcryptSize        = 23
+	bcryptDefaultCost = 10
+
+	scryptDefaultN = 16384
+	scryptDefaultR = 8
+	scryptDefaultP = 1
+
+	// the defaults of the argon2 reference implementation
+	argon2DefaultTime    = 3
+	argon2DefaultMemory  = 4096
+	argon2DefaultThreads = 1
+
+	pbkdf2DefaultRounds = 10000
+)
+
+func newBcrypt() hash.Hash {
+	h, _ := newBcryptWithParams(HashParams{})
+	return h
+}
+
+// bcrypt takes a cost (4-31) and a 16 byte salt
+func newBcryptWithParams(p HashParams) (hash.Hash, error) {
+	if err := p.allow("cost", "salt"); err != nil {
+		return nil, err
+	}
+	cost, err := p.int("cost", bcryptDefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	if cost < 4 || cost > 31 {
+		return nil, fmt.Errorf("cost must be 4 to 31, is %d", cost)
+	}
+	salt, err := p.bytes("salt")
+	if err != nil {
+		return nil, err
+	}
+	if salt == nil {
+		salt = make([]byte, bcryptSaltSize)
+	}
+	if len(salt) != bcryptSaltSize {
+		return nil, fmt.Errorf("salt must be %d bytes, is %d", bcryptSaltSize, len(salt))
+	}
+	return newBufferedHash(bcryptSize, func(password []byte) []byte {
+		return bcryptHash(password, cost, salt)
+	}), nil
+}
+
+// bcryptHash returns the 23 byte bcrypt hash, as x/crypto/bcrypt only hashes with random salts
+func bcryptHash(password []byte, cost int, salt []byte) []byte {
+	// the trailing zero of the C string is part of the key
+	key := append(password[:len(password):len(password)], 0)
+	c, _ := blowfish.NewSaltedCipher(key, salt)
+	for i := uint64(0); i < 1<<uint(cost); i++ {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+
+	data := []byte("OrpheanBeholderScryDoubt")
+	for i := 0; i < len(data); i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(data[i:i+8], data[i:i+8])
+		}
+	}
+	// only 23 of the 24 bytes are used, for compatibility with the original implementation
+	return data[:bcryptSize]
+}
+
+func newScrypt() hash.Hash {
+	h, _ := newScryptWithParams(HashParams{})
+	return h
+}
+
+// scrypt takes the cost n (a power of 2), block size r, parallelism p, a salt and an output size in bytes
+func newScryptWithParams(p HashParams) (hash.Hash, error) {
+	if err := p.allow("n", "r", "p", "salt", "output"); err != nil {
+		return nil, err
+	}
+	n, err := p.int("n", scryptDefaultN)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.int("r", scryptDefaultR)
+	if err != nil {
+		return nil, err
+	}
+	par, err := p.int("p", scryptDefaultP)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := p.bytes("salt")
+	if err != nil {
+		return nil, err
+	}
+	output, err := p.output(32)
+	if err != nil {
+		return nil, err
+	}
+	// the limits checked by scrypt.Key, so it can't fail later
+	if n <= 1 || n&(n-1) != 0 || r < 1 || par < 1 || uint64(r)*uint64(par) >= 1<<30 {
+		return nil, fmt.Errorf("invalid parameters n=%d, r=%d, p=%d", n, r, par)
+	}
+	return newBufferedHash(output, func(password []byte) []byte {
+		key, _ := scrypt.Key(password, salt, n, r, par, output)
+		return key
+	}), nil
+}
+
+func newArgon2(id bool) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newArgon2WithParams(id)(HashParams{})
+		return h
+	}
+}
+
+// argon2 takes time t, memory m in KiB, threads p, a salt and an output size in bytes
+func newArgon2WithParams(id bool) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("t", "m", "p", "salt", "output"); err != nil {
+			return nil, err
+		}
+		passes, err := p.int("t", argon2DefaultTime)
+		if err != nil {
+			return nil, err
+		}
+		memory, err := p.int("m", argon2DefaultMemory)
+		if err != nil {
+			return nil, err
+		}
+		threads, err := p.int("p", argon2DefaultThreads)
+		if err != nil {
+			return nil, err
+		}
+		if passes < 1 || threads < 1 || threads > 255 || memory < 8*threads {
+			return nil, fmt.Errorf("invalid parameters t=%d, m=%d, p=%d", passes, memory, threads)
+		}
+		salt, err := p.bytes("salt")
+		if err != nil {
+			return nil, err
+		}
+		output, err := p.output(32)
+		if err != nil {
+			return nil, err
+		}
+		return newBufferedHash(output, func(password []byte) []byte {
+			if id {
+				return argon2.IDKey(password, salt, uint32(passes), uint32(memory), uint8(threads), uint32(output))
+			}
+			return argon2.Key(password, salt, uint32(passes), uint32(memory), uint8(threads), uint32(output))
+		}), nil
+	}
+}
+
+func newPBKDF2(h func() hash.Hash) func() hash.Hash {
+	return func() hash.Hash {
+		res, _ := newPBKDF2WithParams(h)(HashParams{})
+		return res
+	}
+}
+
+// pbkdf2 takes a number of rounds, a salt and an output size in bytes
+func newPBKDF2WithParams(h func() hash.Hash) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("rounds", "salt", "output"); err != nil {
+			return nil, err
+		}
+		rounds, err := p.int("rounds", pbkdf2DefaultRounds)
+		if err != nil {
+			return nil, err
+		}
+		if rounds < 1 {
+			return nil, fmt.Errorf("invalid rounds %d", rounds)
+		}
+		salt, err := p.bytes("salt")
+		if err != nil {
+			return nil, err
+		}
+		output, err := p.output(h().Size())
+		if err != nil {
+			return nil, err
+		}
+		return newBufferedHash(output, func(password []byte) []byte {
+			return pbkdf2.Key(password, salt, rounds, output, h)
+		}), nil
+	}
+}
+
+var passwordHashes = []HashAlgorithm{
+	{Name: "argon2i", Bits: 256, Family: "password", VariableSize: true, New: newArgon2(false), NewWithParams: newArgon2WithParams(false)},
+	{Name: "argon2id", Bits: 256, Family: "password", VariableSize: true, New: newArgon2(true), NewWithParams: newArgon2WithParams(true)},
+	{Name: "bcrypt", Bits: bcryptSize * 8, Family: "password", New: newBcrypt, NewWithParams: newBcryptWithParams},
+	{Name: "pbkdf2-sha1", Bits: 160, Family: "password", VariableSize: true, New: newPBKDF2(sha1.New), NewWithParams: newPBKDF2WithParams(sha1.New)},
+	{Name: "pbkdf2-sha256", Bits: 256, Family: "password", VariableSize: true, New: newPBKDF2(sha256.New), NewWithParams: newPBKDF2WithParams(sha256.New)},
+	{Name: "pbkdf2-sha512", Bits: 512, Family: "password", VariableSize: true, New: newPBKDF2(sha512.New), NewWithParams: newPBKDF2WithParams(sha512.New)},
+	{Name: "scrypt", Bits: 256, Family: "password", VariableSize: true, New: newScrypt, NewWithParams: newScryptWithParams},
+}
+
+func init() {
+	for _, algo := range passwordHashes {
+		mustRegisterHash(algo)
+	}
+}
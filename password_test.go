@@ -0,0 +1,90 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHashes(t *testing.T) {
+	somesalt := hex.EncodeToString([]byte("somesalt"))
+	for _, tc := range []struct {
+		algo     string
+		password string
+		expected string
+	}{
+		// from the argon2 reference implementation
+		{"argon2i:t=2,m=65536,p=1,salt=" + somesalt, "password", "c1628832147d9720c5bd1cfd61367078729f6dfb6f8fea9ff98158e0d7816ed0"},
+		{"argon2id:t=2,m=65536,p=1,salt=" + somesalt, "password", "09316115d5cf24ed5a15a31a3ba326e5cf32edc24702987c02b6566f61913cf7"},
+		// RFC 6070
+		{"pbkdf2-sha1:rounds=4096,salt=" + hex.EncodeToString([]byte("salt")), "password", "4b007901b765489abead49d926f721d065a429c1"},
+		// RFC 7914
+		{"scrypt:n=16,r=1,p=1,output=64", "", "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"scrypt:n=1024,r=8,p=16,output=64,salt=" + hex.EncodeToString([]byte("NaCl")), "password", "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+	} {
+		calc := NewCalculator(strings.NewReader(tc.password))
+		sum, err := calc.Sum(tc.algo)
+		assert.Equal(t, nil, err, tc.algo)
+		assert.Equal(t, tc.expected, hex.EncodeToString(sum), tc.algo)
+	}
+}
+
+func TestBcrypt(t *testing.T) {
+	// from the OpenBSD bcrypt tests
+	mcf := "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+	salt, _ := bcryptEncoding.DecodeString(mcf[7:29])
+	expected, _ := bcryptEncoding.DecodeString(mcf[29:])
+
+	calc := NewCalculator(strings.NewReader("U*U"))
+	sum, err := calc.Sum("bcrypt:cost=5,salt=" + hex.EncodeToString(salt[:bcryptSaltSize]))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expected, sum)
+
+	// compare with x/crypto/bcrypt, which always uses a random salt
+	password := []byte("correct horse battery staple")
+	generated, _ := bcrypt.GenerateFromPassword(password, 4)
+	salt, _ = bcryptEncoding.DecodeString(string(generated[7:29]))
+	h, err := NewHash("bcrypt:cost=4,salt=" + hex.EncodeToString(salt[:bcryptSaltSize]))
+	assert.Equal(t, nil, err)
+	_, _ = h.Write(password)
+	assert.Equal(t, string(generated[29:]), bcryptEncoding.EncodeToString(h.Sum(nil)))
+
+	for _, algo := range []string{"bcrypt:cost=3", "bcrypt:cost=32", "bcrypt:salt=00ff", "bcrypt:rounds=5"} {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+}
+
+func TestPasswordHashErrors(t *testing.T) {
+	for _, algo := range []string{
+		"scrypt:n=1000", "scrypt:r=0", "scrypt:output=0",
+		"argon2id:t=0", "argon2id:p=0", "argon2i:m=4",
+		"pbkdf2-sha256:rounds=0", "pbkdf2-sha256:salt=xx",
+	} {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+
+	// password hashes have no HMAC
+	_, err := NewHMAC("pbkdf2-sha256", []byte("key"))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestCalculatorVerify(t *testing.T) {
+	algo := "pbkdf2-sha256:rounds=1000,salt=73616c74"
+	sum, _ := NewCalculator(strings.NewReader("hunter2")).Sum(algo)
+
+	ok, err := NewCalculator(strings.NewReader("hunter2")).Verify(algo, sum)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, ok)
+
+	ok, err = NewCalculator(strings.NewReader("hunter3")).Verify(algo, sum)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	_, err = NewCalculator(strings.NewReader("hunter2")).Verify("nope", sum)
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,110 @@
+package gohash
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// SumOptions are the options of SumContext and SumManyContext
+type SumOptions struct {
+	// Progress is called with the progress every ProgressInterval, and once when done
+	Progress func(Progress)
+
+	// ProgressInterval defaults to 100 ms
+	ProgressInterval time.Duration
+}
+
+// Progress is the progress of a checksum calculation
+type Progress struct {
+	Bytes   uint64
+	Elapsed time.Duration
+}
+
+// BytesPerSecond returns the throughput so far
+func (p Progress) BytesPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Bytes) / p.Elapsed.Seconds()
+}
+
+const (
+	defaultProgressInterval = 100 * time.Millisecond
+)
+
+// progressReader checks for cancellation before each read and reports progress
+type progressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	progress func(Progress)
+	interval time.Duration
+
+	start      time.Time
+	lastReport time.Time
+	bytes      uint64
+}
+
+func newProgressReader(ctx context.Context, reader io.Reader, opts *SumOptions) *progressReader {
+	r := &progressReader{ctx: ctx, reader: reader, interval: defaultProgressInterval, start: time.Now()}
+	if opts != nil {
+		r.progress = opts.Progress
+		if opts.ProgressInterval > 0 {
+			r.interval = opts.ProgressInterval
+		}
+	}
+	r.lastReport = r.start
+	return r
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	r.bytes += uint64(n)
+	if r.progress != nil && time.Since(r.lastReport) >= r.interval {
+		r.report()
+	}
+	return n, err
+}
+
+func (r *progressReader) report() {
+	r.lastReport = time.Now()
+	r.progress(Progress{Bytes: r.bytes, Elapsed: r.lastReport.Sub(r.start)})
+}
+
+// called when all input is read
+func (r *progressReader) done() {
+	if r.progress != nil {
+		r.report()
+	}
+}
+
+// SumContext is Sum, stopping with the error of `ctx` when it is done. The
+// context is checked between reads, so a blocked read of the input is not
+// interrupted. `opts` may be nil
+func (c *Calculator) SumContext(ctx context.Context, algo string, opts *SumOptions) ([]byte, error) {
+	h, _, err := c.newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+	r := newProgressReader(ctx, c.reader, opts)
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	r.done()
+	return h.Sum(nil), nil
+}
+
+// SumManyContext is SumMany, stopping with the error of `ctx` when it is done.
+// `opts` may be nil
+func (c *Calculator) SumManyContext(ctx context.Context, ids []string, opts *SumOptions) (map[string][]byte, error) {
+	r := newProgressReader(ctx, c.reader, opts)
+	res, err := c.sumMany(r, ids)
+	if err != nil {
+		return nil, err
+	}
+	r.done()
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package gohash
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSumContext(t *testing.T) {
+	reports := []Progress{}
+	opts := &SumOptions{Progress: func(p Progress) { reports = append(reports, p) }}
+	sum, err := NewCalculator(strings.NewReader(fox)).SumContext(context.Background(), "sha1", opts)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedHashes["sha1"][fox], hex.EncodeToString(sum))
+	assert.Equal(t, uint64(len(fox)), reports[len(reports)-1].Bytes)
+
+	sum, err = NewCalculator(strings.NewReader(fox)).SumContext(context.Background(), "sha1", nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedHashes["sha1"][fox], hex.EncodeToString(sum))
+}
+
+func TestSumManyContext(t *testing.T) {
+	var last Progress
+	opts := &SumOptions{Progress: func(p Progress) { last = p }, ProgressInterval: time.Nanosecond}
+	ids := []string{"md5", "sha1"}
+	sums, err := NewCalculator(&patternReader{n: 3 * sumManyChunkSize}).SumManyContext(context.Background(), ids, opts)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(sums))
+	assert.Equal(t, uint64(3*sumManyChunkSize), last.Bytes)
+}
+
+func TestSumContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewCalculator(strings.NewReader(fox)).SumContext(ctx, "sha1", nil)
+	assert.Equal(t, context.Canceled, err)
+
+	// cancel while hashing
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	reads := 0
+	opts := &SumOptions{
+		Progress: func(p Progress) {
+			reads++
+			if reads == 3 {
+				cancel()
+			}
+		},
+		ProgressInterval: time.Nanosecond,
+	}
+	_, err = NewCalculator(&patternReader{n: 64 << 20}).SumManyContext(ctx, []string{"sha1"}, opts)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 3, reads)
+}
+
+func TestProgressBytesPerSecond(t *testing.T) {
+	assert.Equal(t, float64(0), Progress{}.BytesPerSecond())
+	assert.Equal(t, float64(2048), Progress{Bytes: 1024, Elapsed: time.Second / 2}.BytesPerSecond())
+}
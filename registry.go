@@ -0,0 +1,131 @@
+package gohash
+
+import (
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HashAlgorithm describes a hash algorithm known to gohash
+type HashAlgorithm struct {
+	// Name is the id used to select the algorithm, eg "sha256"
+	Name string
+
+	// Aliases are alternative id's for the algorithm, eg "crc32" for "crc32-ieee"
+	Aliases []string
+
+	// Bits is the digest size in bits
+	Bits int
+
+	// Family groups related algorithms, eg "sha2" or "crc"
+	Family string
+
+	// VariableSize is set if the digest size can be chosen with the "output" parameter,
+	// making the algorithm a candidate for any digest size
+	VariableSize bool
+
+	// New returns a new hash.Hash computing the algorithm
+	New func() hash.Hash
+
+	// NewWithParams returns a new hash.Hash computing the algorithm using
+	// parameters such as "key" or "output", only set for algorithms taking parameters
+	NewWithParams func(params HashParams) (hash.Hash, error)
+}
+
+var (
+	registryMutex = &sync.RWMutex{}
+	registry      = map[string]*HashAlgorithm{}
+	registryAlias = map[string]string{}
+)
+
+// RegisterHash makes a hash algorithm available to Calculator, Hasher and Dictionary
+func RegisterHash(algo HashAlgorithm) error {
+
+	if algo.Name == "" {
+		return fmt.Errorf("algo name unset")
+	}
+	if algo.Bits <= 0 {
+		return fmt.Errorf("%s: digest size unset", algo.Name)
+	}
+	if algo.New == nil {
+		return fmt.Errorf("%s: constructor unset", algo.Name)
+	}
+
+	algo.Name = strings.ToLower(algo.Name)
+	ids := []string{algo.Name}
+	for _, alias := range algo.Aliases {
+		ids = append(ids, strings.ToLower(alias))
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, id := range ids {
+		if _, ok := registry[id]; ok {
+			return fmt.Errorf("%s: id %s already registered", algo.Name, id)
+		}
+		if _, ok := registryAlias[id]; ok {
+			return fmt.Errorf("%s: id %s already registered", algo.Name, id)
+		}
+	}
+
+	registry[algo.Name] = &algo
+	for _, id := range ids[1:] {
+		registryAlias[id] = algo.Name
+	}
+	return nil
+}
+
+func mustRegisterHash(algo HashAlgorithm) {
+	if err := RegisterHash(algo); err != nil {
+		panic(err)
+	}
+}
+
+// LookupHash returns the registered algorithm for `id`, which may be an alias.
+// Id's are case insensitive
+func LookupHash(id string) (HashAlgorithm, bool) {
+	algo, ok := lookupHash(id)
+	if !ok {
+		return HashAlgorithm{}, false
+	}
+	return *algo, true
+}
+
+func lookupHash(id string) (*HashAlgorithm, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	algo, ok := registry[resolveAlgoAliasesLocked(id)]
+	return algo, ok
+}
+
+// returns all registered algorithms, sorted by name
+func registeredHashes() []*HashAlgorithm {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	res := []*HashAlgorithm{}
+	for _, algo := range registry {
+		res = append(res, algo)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func resolveAlgoAliases(s string) string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	return resolveAlgoAliasesLocked(s)
+}
+
+func resolveAlgoAliasesLocked(s string) string {
+	s = strings.ToLower(s)
+	if name, ok := registryAlias[s]; ok {
+		return name
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package gohash
+
+import (
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// xor8 is a minimal hash.Hash used to test registration
+type xor8 struct{ sum byte }
+
+func (h *xor8) Write(p []byte) (int, error) {
+	for _, b := range p {
+		h.sum ^= b
+	}
+	return len(p), nil
+}
+func (h *xor8) Sum(b []byte) []byte { return append(b, h.sum) }
+func (h *xor8) Reset()              { h.sum = 0 }
+func (h *xor8) Size() int           { return 1 }
+func (h *xor8) BlockSize() int      { return 1 }
+
+func registerTestHash(t *testing.T) {
+	err := RegisterHash(HashAlgorithm{
+		Name:    "test-xor8",
+		Aliases: []string{"txor"},
+		Bits:    8,
+		Family:  "test",
+		New:     func() hash.Hash { return &xor8{} },
+	})
+	assert.Equal(t, nil, err)
+
+	t.Cleanup(func() {
+		registryMutex.Lock()
+		defer registryMutex.Unlock()
+		delete(registry, "test-xor8")
+		delete(registryAlias, "txor")
+	})
+}
+
+func TestRegisterHash(t *testing.T) {
+	registerTestHash(t)
+
+	assert.Equal(t, true, isStringInSlice("test-xor8", AvailableHashes()))
+
+	algo, ok := LookupHash("txor")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "test-xor8", algo.Name)
+	assert.Equal(t, "test", algo.Family)
+
+	calc := NewCalculator(strings.NewReader("hej"))
+	sum, err := calc.Sum("txor")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "67", hex.EncodeToString(sum))
+
+	hasher := NewHasher()
+	hasher.Algo("test-xor8")
+	hasher.Length(1)
+	hasher.AllowedKeys("abcdef")
+	hasher.ExpectedHash("65")
+	res, err := hasher.FindSequential()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "e", res)
+}
+
+func TestRegisterHashErrors(t *testing.T) {
+	newFn := func() hash.Hash { return &xor8{} }
+
+	assert.NotEqual(t, nil, RegisterHash(HashAlgorithm{Bits: 8, New: newFn}))
+	assert.NotEqual(t, nil, RegisterHash(HashAlgorithm{Name: "x", New: newFn}))
+	assert.NotEqual(t, nil, RegisterHash(HashAlgorithm{Name: "x", Bits: 8}))
+
+	// name and aliases must not collide with existing ids
+	assert.NotEqual(t, nil, RegisterHash(HashAlgorithm{Name: "sha1", Bits: 8, New: newFn}))
+	assert.NotEqual(t, nil, RegisterHash(HashAlgorithm{Name: "x", Aliases: []string{"crc32"}, Bits: 8, New: newFn}))
+	_, ok := LookupHash("x")
+	assert.Equal(t, false, ok)
+}
@@ -0,0 +1,157 @@
+package gohash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/bits"
+)
+
+// sipHash implements SipHash-c-d with 64 or 128 bit output,
+// see https://www.aumasson.jp/siphash/siphash.pdf
+type sipHash struct {
+	k0, k1         uint64
+	v0, v1, v2, v3 uint64
+	cRounds        int
+	dRounds        int
+	size           int
+
+	x      [8]byte
+	nx     int
+	length uint64
+}
+
+// newSipHash returns a SipHash-c-d using 16 byte `key`, with output `size` of 8 or 16 bytes
+func newSipHash(cRounds, dRounds, size int, key []byte) (hash.Hash, error) {
+	if len(key) != 16 {
+		return nil, fmt.Errorf("siphash key must be 16 bytes, is %d", len(key))
+	}
+	d := &sipHash{
+		k0:      binary.LittleEndian.Uint64(key[0:]),
+		k1:      binary.LittleEndian.Uint64(key[8:]),
+		cRounds: cRounds,
+		dRounds: dRounds,
+		size:    size,
+	}
+	d.Reset()
+	return d, nil
+}
+
+// NOTE the unkeyed siphash variants use an empty key
+var siphashEmptyKey = make([]byte, 16)
+
+func newSiphash(cRounds, dRounds, size int) func() hash.Hash {
+	return func() hash.Hash {
+		h, _ := newSipHash(cRounds, dRounds, size, siphashEmptyKey)
+		return h
+	}
+}
+
+// siphash takes a 16 byte key
+func newSiphashWithParams(cRounds, dRounds, size int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("key"); err != nil {
+			return nil, err
+		}
+		key, err := p.bytes("key")
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			key = siphashEmptyKey
+		}
+		return newSipHash(cRounds, dRounds, size, key)
+	}
+}
+
+func (d *sipHash) Reset() {
+	d.v0 = d.k0 ^ 0x736f6d6570736575
+	d.v1 = d.k1 ^ 0x646f72616e646f6d
+	d.v2 = d.k0 ^ 0x6c7967656e657261
+	d.v3 = d.k1 ^ 0x7465646279746573
+	if d.size == 16 {
+		d.v1 ^= 0xee
+	}
+	d.nx = 0
+	d.length = 0
+}
+
+func (d *sipHash) Size() int      { return d.size }
+func (d *sipHash) BlockSize() int { return 8 }
+
+func (d *sipHash) round() {
+	d.v0 += d.v1
+	d.v1 = bits.RotateLeft64(d.v1, 13)
+	d.v1 ^= d.v0
+	d.v0 = bits.RotateLeft64(d.v0, 32)
+	d.v2 += d.v3
+	d.v3 = bits.RotateLeft64(d.v3, 16)
+	d.v3 ^= d.v2
+	d.v0 += d.v3
+	d.v3 = bits.RotateLeft64(d.v3, 21)
+	d.v3 ^= d.v0
+	d.v2 += d.v1
+	d.v1 = bits.RotateLeft64(d.v1, 17)
+	d.v1 ^= d.v2
+	d.v2 = bits.RotateLeft64(d.v2, 32)
+}
+
+func (d *sipHash) compress(m uint64) {
+	d.v3 ^= m
+	for i := 0; i < d.cRounds; i++ {
+		d.round()
+	}
+	d.v0 ^= m
+}
+
+func (d *sipHash) Write(p []byte) (int, error) {
+	n := len(p)
+	d.length += uint64(n)
+
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		p = p[c:]
+		if d.nx < 8 {
+			return n, nil
+		}
+		d.compress(binary.LittleEndian.Uint64(d.x[:]))
+		d.nx = 0
+	}
+	for len(p) >= 8 {
+		d.compress(binary.LittleEndian.Uint64(p))
+		p = p[8:]
+	}
+	d.nx = copy(d.x[:], p)
+	return n, nil
+}
+
+func (d *sipHash) Sum(b []byte) []byte {
+	// finalize a copy, so more data can be written afterwards
+	f := *d
+
+	var last [8]byte
+	copy(last[:], f.x[:f.nx])
+	m := binary.LittleEndian.Uint64(last[:]) | f.length<<56
+	f.compress(m)
+
+	if f.size == 16 {
+		f.v2 ^= 0xee
+	} else {
+		f.v2 ^= 0xff
+	}
+	for i := 0; i < f.dRounds; i++ {
+		f.round()
+	}
+	var out [16]byte
+	binary.LittleEndian.PutUint64(out[0:], f.v0^f.v1^f.v2^f.v3)
+
+	if f.size == 16 {
+		f.v1 ^= 0xdd
+		for i := 0; i < f.dRounds; i++ {
+			f.round()
+		}
+		binary.LittleEndian.PutUint64(out[8:], f.v0^f.v1^f.v2^f.v3)
+	}
+	return append(b, out[:f.size]...)
+}
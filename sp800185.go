@@ -0,0 +1,267 @@
+package gohash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// functions from NIST SP 800-185, built on cSHAKE,
+// see https://nvlpubs.nist.gov/nistpubs/SpecialPublications/NIST.SP.800-185.pdf
+
+const (
+	rateCShake128 = 168
+	rateCShake256 = 136
+)
+
+func leftEncode(x uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[1:], x)
+	i := 1
+	for i < 8 && b[i] == 0 {
+		i++
+	}
+	b[i-1] = byte(9 - i)
+	return b[i-1:]
+}
+
+func rightEncode(x uint64) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[:8], x)
+	i := 0
+	for i < 7 && b[i] == 0 {
+		i++
+	}
+	b[8] = byte(8 - i)
+	return b[i:]
+}
+
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s))*8), s...)
+}
+
+func bytepad(x []byte, w int) []byte {
+	buf := append(leftEncode(uint64(w)), x...)
+	if pad := len(buf) % w; pad != 0 {
+		buf = append(buf, make([]byte, w-pad)...)
+	}
+	return buf
+}
+
+func newCShake(security int, functionName, custom []byte) sha3.ShakeHash {
+	if security == 128 {
+		return sha3.NewCShake128(functionName, custom)
+	}
+	return sha3.NewCShake256(functionName, custom)
+}
+
+// kmac is a hash.Hash computing KMAC128 or KMAC256
+type kmac struct {
+	sha3.ShakeHash
+	keyBlock []byte
+	size     int
+}
+
+func newKMAC(security int, key, custom []byte, size int) *kmac {
+	rate := rateCShake256
+	if security == 128 {
+		rate = rateCShake128
+	}
+	h := &kmac{
+		ShakeHash: newCShake(security, []byte("KMAC"), custom),
+		keyBlock:  bytepad(encodeString(key), rate),
+		size:      size,
+	}
+	_, _ = h.ShakeHash.Write(h.keyBlock)
+	return h
+}
+
+func (h *kmac) Reset() {
+	h.ShakeHash.Reset()
+	_, _ = h.ShakeHash.Write(h.keyBlock)
+}
+
+func (h *kmac) Size() int { return h.size }
+
+func (h *kmac) Sum(b []byte) []byte {
+	d := h.Clone()
+	_, _ = d.Write(rightEncode(uint64(h.size) * 8))
+	res := make([]byte, h.size)
+	_, _ = d.Read(res)
+	return append(b, res...)
+}
+
+// TupleHash returns the TupleHash128 or TupleHash256 of `tuple`, with output `size` in bytes
+func TupleHash(security int, tuple [][]byte, custom []byte, size int) ([]byte, error) {
+	if security != 128 && security != 256 {
+		return nil, fmt.Errorf("security must be 128 or 256")
+	}
+	h := newCShake(security, []byte("TupleHash"), custom)
+	for _, element := range tuple {
+		_, _ = h.Write(encodeString(element))
+	}
+	_, _ = h.Write(rightEncode(uint64(size) * 8))
+	res := make([]byte, size)
+	_, _ = h.Read(res)
+	return res, nil
+}
+
+// SumTuple returns the TupleHash of `tuple` for algo id "tuplehash128" or "tuplehash256",
+// which takes a customization string and an output size in bytes, as in
+// "tuplehash128:custom=My App,output=16". TupleHash is not a streaming hash, as each
+// element is prefixed with its length
+func SumTuple(algo string, tuple [][]byte) ([]byte, error) {
+	name, p, err := parseAlgoSpec(algo)
+	if err != nil {
+		return nil, err
+	}
+	security := 0
+	switch name {
+	case "tuplehash128":
+		security = 128
+	case "tuplehash256":
+		security = 256
+	default:
+		return nil, fmt.Errorf("unknown tuple hash %s", name)
+	}
+	if err := p.allow("output", "custom"); err != nil {
+		return nil, err
+	}
+	output, err := p.output(security / 4)
+	if err != nil {
+		return nil, err
+	}
+	return TupleHash(security, tuple, []byte(p["custom"]), output)
+}
+
+// parallelHash is a hash.Hash computing ParallelHash128 or ParallelHash256
+type parallelHash struct {
+	sha3.ShakeHash
+	security  int
+	blockSize int
+	block     []byte
+	blocks    uint64
+	size      int
+}
+
+func newParallelHash(security int, custom []byte, blockSize int, size int) *parallelHash {
+	h := &parallelHash{
+		ShakeHash: newCShake(security, []byte("ParallelHash"), custom),
+		security:  security,
+		blockSize: blockSize,
+		size:      size,
+	}
+	_, _ = h.ShakeHash.Write(leftEncode(uint64(blockSize)))
+	return h
+}
+
+// hashes a complete block into the main state
+func (h *parallelHash) writeBlock(block []byte) {
+	d := newCShake(h.security, nil, nil)
+	_, _ = d.Write(block)
+	res := make([]byte, h.security/4)
+	_, _ = d.Read(res)
+	_, _ = h.ShakeHash.Write(res)
+	h.blocks++
+}
+
+func (h *parallelHash) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(h.block) > 0 {
+		c := h.blockSize - len(h.block)
+		if c > len(p) {
+			c = len(p)
+		}
+		h.block = append(h.block, p[:c]...)
+		p = p[c:]
+		if len(h.block) < h.blockSize {
+			return n, nil
+		}
+		h.writeBlock(h.block)
+		h.block = h.block[:0]
+	}
+	for len(p) >= h.blockSize {
+		h.writeBlock(p[:h.blockSize])
+		p = p[h.blockSize:]
+	}
+	h.block = append(h.block, p...)
+	return n, nil
+}
+
+func (h *parallelHash) Reset() {
+	h.ShakeHash.Reset()
+	_, _ = h.ShakeHash.Write(leftEncode(uint64(h.blockSize)))
+	h.block = h.block[:0]
+	h.blocks = 0
+}
+
+func (h *parallelHash) Size() int { return h.size }
+
+func (h *parallelHash) Sum(b []byte) []byte {
+	f := *h
+	f.ShakeHash = h.Clone()
+	if len(h.block) > 0 {
+		f.writeBlock(h.block)
+	}
+	_, _ = f.ShakeHash.Write(rightEncode(f.blocks))
+	_, _ = f.ShakeHash.Write(rightEncode(uint64(h.size) * 8))
+	res := make([]byte, h.size)
+	_, _ = f.ShakeHash.Read(res)
+	return append(b, res...)
+}
+
+// shake and cshake take an output size in bytes, cshake also takes
+// a function name and a customization string
+func newShakeWithParams(security int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("output", "name", "custom"); err != nil {
+			return nil, err
+		}
+		output, err := p.output(security / 4)
+		if err != nil {
+			return nil, err
+		}
+		return newXOF(newCShake(security, []byte(p["name"]), []byte(p["custom"])), output), nil
+	}
+}
+
+// kmac takes a key, a customization string and an output size in bytes
+func newKMACWithParams(security int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("output", "key", "custom"); err != nil {
+			return nil, err
+		}
+		output, err := p.output(security / 4)
+		if err != nil {
+			return nil, err
+		}
+		key, err := p.bytes("key")
+		if err != nil {
+			return nil, err
+		}
+		return newKMAC(security, key, []byte(p["custom"]), output), nil
+	}
+}
+
+// parallelhash takes a customization string, a block size and an output size in bytes
+func newParallelHashWithParams(security int) func(HashParams) (hash.Hash, error) {
+	return func(p HashParams) (hash.Hash, error) {
+		if err := p.allow("output", "custom", "blocksize"); err != nil {
+			return nil, err
+		}
+		output, err := p.output(security / 4)
+		if err != nil {
+			return nil, err
+		}
+		blockSize, err := p.int("blocksize", 8192)
+		if err != nil {
+			return nil, err
+		}
+		if blockSize < 1 {
+			return nil, fmt.Errorf("invalid block size %d", blockSize)
+		}
+		return newParallelHash(security, []byte(p["custom"]), blockSize, output), nil
+	}
+}
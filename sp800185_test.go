@@ -0,0 +1,129 @@
+package gohash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// returns the bytes from..to inclusive
+func byteRange(from, to int) []byte {
+	res := []byte{}
+	for i := from; i <= to; i++ {
+		res = append(res, byte(i))
+	}
+	return res
+}
+
+func TestSP800185(t *testing.T) {
+	kmacKey := hex.EncodeToString(byteRange(0x40, 0x5f))
+	parallelInput := append(append(byteRange(0x00, 0x07), byteRange(0x10, 0x17)...), byteRange(0x20, 0x27)...)
+
+	tests := []struct {
+		algo     string
+		input    []byte
+		expected string
+	}{
+		// SHAKE value from python hashlib
+		{"shake256:output=100", []byte(fox),
+			"2f671343d9b2e1604dc9dcf0753e5fe15c7c64a0d283cbbf722d411a0e36f6ca1d01d1369a23539cd80f7c054b6e5daf9c962cad5b8ed5bd11998b40d5734442bed798f6e5c915bd8bb07e0188d0a55c1290074f1c287af06352299184492cbdec9acba7"},
+		{"shake128:output=4", []byte("hej"), "e4ceb29e"},
+
+		// values from the NIST SP 800-185 examples
+		{"cshake128:custom=Email Signature", byteRange(0x00, 0x03),
+			"c1c36925b6409a04f1b504fcbca9d82b4017277cb5ed2b2065fc1d3814d5aaf5"},
+		{"cshake256:custom=Email Signature", byteRange(0x00, 0x03),
+			"d008828e2b80ac9d2218ffee1d070c48b8e4c87bff32c9699d5b6896eee0edd164020e2be0560858d9c00c037e34a96937c561a74c412bb4c746469527281c8c"},
+		{"kmac128:key=" + kmacKey, byteRange(0x00, 0x03),
+			"e5780b0d3ea6f7d3a429c5706aa43a00fadbd7d49628839e3187243f456ee14e"},
+		{"kmac128:key=" + kmacKey + ",custom=My Tagged Application", byteRange(0x00, 0x03),
+			"3b1fba963cd8b0b59e8c1a6d71888b7143651af8ba0a7070c0979e2811324aa5"},
+		{"kmac256:key=" + kmacKey + ",custom=My Tagged Application", byteRange(0x00, 0x03),
+			"20c570c31346f703c9ac36c61c03cb64c3970d0cfc787e9b79599d273a68d2f7f69d4cc3de9d104a351689f27cf6f5951f0103f33f4f24871024d9c27773a8dd"},
+		{"parallelhash128:blocksize=8", parallelInput,
+			"ba8dc1d1d979331d3f813603c67f72609ab5e44b94a0b8f9af46514454a2b4f5"},
+		{"parallelhash128:blocksize=8,custom=Parallel Data", parallelInput,
+			"fc484dcb3f84dceedc353438151bee58157d6efed0445a81f165e495795b7206"},
+	}
+	for _, test := range tests {
+		calc := NewCalculator(bytes.NewReader(test.input))
+		res, err := calc.Sum(test.algo)
+		assert.Equal(t, nil, err, test.algo)
+		assert.Equal(t, test.expected, hex.EncodeToString(res), test.algo)
+	}
+
+	invalid := []string{
+		"shake128:output=0",
+		"shake256:output=1048577",
+		"kmac128:key=nothex",
+		"parallelhash128:blocksize=0",
+	}
+	for _, algo := range invalid {
+		_, err := NewHash(algo)
+		assert.NotEqual(t, nil, err, algo)
+	}
+}
+
+func TestTupleHash(t *testing.T) {
+	tuple := [][]byte{byteRange(0x00, 0x02), byteRange(0x10, 0x15), byteRange(0x20, 0x28)}
+
+	res, err := TupleHash(128, tuple, []byte("My Tuple App"), 32)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "e60f202c89a2631eda8d4c588ca5fd07f39e5151998deccf973adb3804bb6e84", hex.EncodeToString(res))
+
+	res, err = TupleHash(256, tuple[:2], nil, 64)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "cfb7058caca5e668f81a12a20a2195ce97a925f1dba3e7449a56f82201ec607311ac2696b1ab5ea2352df1423bde7bd4bb78c9aed1a853c78672f9eb23bbe194", hex.EncodeToString(res))
+
+	// elements are not simply concatenated
+	joined, _ := TupleHash(128, [][]byte{[]byte("ab"), []byte("c")}, nil, 32)
+	split, _ := TupleHash(128, [][]byte{[]byte("a"), []byte("bc")}, nil, 32)
+	assert.NotEqual(t, joined, split)
+
+	_, err = TupleHash(64, tuple, nil, 32)
+	assert.NotEqual(t, nil, err)
+
+	// values from the NIST SP 800-185 examples
+	res, err = SumTuple("tuplehash128", tuple[:2])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "c5d8786c1afb9b82111ab34b65b2c0048fa64e6d48e263264ce1707d3ffc8ed1", hex.EncodeToString(res))
+
+	res, err = SumTuple("tuplehash128:custom=My Tuple App", tuple[:2])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "75cdb20ff4db1154e841d758e24160c54bae86eb8c13e7f5f40eb35588e96dfb", hex.EncodeToString(res))
+
+	res, err = SumTuple("tuplehash256:output=32", tuple)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 32, len(res))
+
+	for _, algo := range []string{"tuplehash128:key=00", "tuplehash128:output=0", "sha1"} {
+		_, err := SumTuple(algo, tuple)
+		assert.NotEqual(t, nil, err, algo)
+	}
+
+	// tuplehash has no streaming form
+	_, err = NewHash("tuplehash128")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParallelHashWrites(t *testing.T) {
+	// result must not depend on how the input is split across writes
+	input := bytes.Repeat([]byte(fox), 20)
+	expected, _ := NewHash("parallelhash256:blocksize=64")
+	_, _ = expected.Write(input)
+
+	for _, n := range []int{1, 7, 64, 100} {
+		h, _ := NewHash("parallelhash256:blocksize=64")
+		for p := input; len(p) > 0; {
+			c := n
+			if c > len(p) {
+				c = len(p)
+			}
+			_, _ = h.Write(p[:c])
+			p = p[c:]
+		}
+		assert.Equal(t, expected.Sum(nil), h.Sum(nil), n)
+	}
+}
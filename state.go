@@ -0,0 +1,178 @@
+package gohash
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// a saved state is stateMagic, the algorithm id, a zero byte, the number of bytes
+// hashed as a big endian uint64 and the state of the hash.Hash
+const stateMagic = "gohash-state\x01"
+
+// returns `algo` with aliases resolved and parameters sorted, so equivalent ids match.
+// Keyed hashes are refused, as their state reveals the key
+func stateID(algo string, keyed bool) (string, error) {
+	name, params, err := parseAlgoSpec(algo)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := params["key"]; ok || keyed {
+		return "", fmt.Errorf("%s with a key does not support saving state", name)
+	}
+	id := resolveAlgoAliases(name)
+	if len(params) > 0 {
+		id += ":" + params.String()
+	}
+	return id, nil
+}
+
+// marshalState returns the state of `h` computing `algo`, after hashing `length` bytes.
+// `keyed` is set if the key is given separately from `algo`
+func marshalState(algo string, h hash.Hash, length uint64, keyed bool) ([]byte, error) {
+	id, err := stateID(algo, keyed)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support saving state", id)
+	}
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	res := append([]byte(stateMagic+id), 0)
+	res = appendUint64(res, length)
+	return append(res, b...), nil
+}
+
+// unmarshalState restores `h` computing `algo` from `state`, returning the number of bytes hashed.
+// `keyed` is set if the key is given separately from `algo`
+func unmarshalState(algo string, h hash.Hash, state []byte, keyed bool) (uint64, error) {
+	id, err := stateID(algo, keyed)
+	if err != nil {
+		return 0, err
+	}
+	savedID, length, b, err := parseState(state)
+	if err != nil {
+		return 0, err
+	}
+	if savedID != id {
+		return 0, fmt.Errorf("state is for %s, not %s", savedID, id)
+	}
+	u, ok := h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return 0, fmt.Errorf("%s does not support saving state", id)
+	}
+	if err := u.UnmarshalBinary(b); err != nil {
+		return 0, fmt.Errorf("%s: %v", id, err)
+	}
+	return length, nil
+}
+
+func parseState(state []byte) (id string, length uint64, b []byte, err error) {
+	if !bytes.HasPrefix(state, []byte(stateMagic)) {
+		return "", 0, nil, fmt.Errorf("invalid state")
+	}
+	state = state[len(stateMagic):]
+	pos := bytes.IndexByte(state, 0)
+	if pos == -1 || len(state) < pos+1+8 {
+		return "", 0, nil, fmt.Errorf("invalid state")
+	}
+	id = string(state[:pos])
+	length = binary.BigEndian.Uint64(state[pos+1:])
+	return id, length, state[pos+1+8:], nil
+}
+
+// StateLength returns the number of bytes hashed before `state` was saved
+func StateLength(state []byte) (uint64, error) {
+	_, length, _, err := parseState(state)
+	return length, err
+}
+
+const (
+	crcStateMagic = "crc\x02"
+	sipStateMagic = "sip\x01"
+)
+
+// returns the crc model parameters, as saved in the state
+func appendCRCModel(b []byte, m CRCModel) []byte {
+	var flags byte
+	if m.RefIn {
+		flags |= 1
+	}
+	if m.RefOut {
+		flags |= 2
+	}
+	b = append(b, byte(m.Width), flags)
+	b = appendUint64(b, m.Poly)
+	b = appendUint64(b, m.Init)
+	return appendUint64(b, m.XorOut)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (h *crcHash) MarshalBinary() ([]byte, error) {
+	b := appendCRCModel([]byte(crcStateMagic), h.model)
+	return appendUint64(b, h.reg), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (h *crcHash) UnmarshalBinary(b []byte) error {
+	model := appendCRCModel(nil, h.model)
+	if len(b) != len(crcStateMagic)+len(model)+8 || string(b[:len(crcStateMagic)]) != crcStateMagic {
+		return fmt.Errorf("invalid crc state")
+	}
+	b = b[len(crcStateMagic):]
+	if !bytes.Equal(b[:len(model)], model) {
+		return fmt.Errorf("crc state is for another model")
+	}
+	h.reg = binary.BigEndian.Uint64(b[len(model):])
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Only the unkeyed variants are
+// supported, as the state reveals the key
+func (d *sipHash) MarshalBinary() ([]byte, error) {
+	if d.k0 != 0 || d.k1 != 0 {
+		return nil, fmt.Errorf("cannot save the state of keyed siphash")
+	}
+	b := []byte(sipStateMagic)
+	b = append(b, byte(d.cRounds), byte(d.dRounds), byte(d.size))
+	for _, v := range []uint64{d.v0, d.v1, d.v2, d.v3, d.length} {
+		b = appendUint64(b, v)
+	}
+	b = append(b, d.x[:d.nx]...)
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (d *sipHash) UnmarshalBinary(b []byte) error {
+	if len(b) < len(sipStateMagic)+3+5*8 || string(b[:len(sipStateMagic)]) != sipStateMagic {
+		return fmt.Errorf("invalid siphash state")
+	}
+	b = b[len(sipStateMagic):]
+	if int(b[0]) != d.cRounds || int(b[1]) != d.dRounds || int(b[2]) != d.size {
+		return fmt.Errorf("siphash state is for another variant")
+	}
+	b = b[3:]
+	d.v0 = binary.BigEndian.Uint64(b[0:])
+	d.v1 = binary.BigEndian.Uint64(b[8:])
+	d.v2 = binary.BigEndian.Uint64(b[16:])
+	d.v3 = binary.BigEndian.Uint64(b[24:])
+	d.length = binary.BigEndian.Uint64(b[32:])
+	b = b[40:]
+	if len(b) >= 8 || len(b) != int(d.length%8) {
+		return fmt.Errorf("invalid siphash state")
+	}
+	d.nx = copy(d.x[:], b)
+	return nil
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], v)
+	return append(b, tmp[:]...)
+}
@@ -0,0 +1,116 @@
+package gohash
+
+import (
+	"encoding"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumeState(t *testing.T) {
+	for algo, forms := range expectedHashes {
+		h, _ := NewHash(algo)
+		if _, ok := h.(encoding.BinaryMarshaler); !ok {
+			continue
+		}
+		for _, split := range []int{0, 7, len(fox)} {
+			_, state, err := NewCalculator(strings.NewReader(fox[:split])).SumState(algo)
+			assert.Equal(t, nil, err, algo)
+
+			length, err := StateLength(state)
+			assert.Equal(t, nil, err, algo)
+			assert.Equal(t, uint64(split), length, algo)
+
+			calc := NewCalculator(strings.NewReader(fox[split:]))
+			calc.ResumeState(state)
+			sum, err := calc.Sum(algo)
+			assert.Equal(t, nil, err, algo)
+			assert.Equal(t, forms[fox], hex.EncodeToString(sum), algo)
+		}
+	}
+}
+
+func TestResumeStateChained(t *testing.T) {
+	var state []byte
+	for _, part := range []string{"The quick ", "brown fox ", "", "jumps over the lazy dog"} {
+		calc := NewCalculator(strings.NewReader(part))
+		if state != nil {
+			calc.ResumeState(state)
+		}
+		var err error
+		_, state, err = calc.SumState("sha256")
+		assert.Equal(t, nil, err)
+	}
+	length, _ := StateLength(state)
+	assert.Equal(t, uint64(len(fox)), length)
+
+	calc := NewCalculator(strings.NewReader(""))
+	calc.ResumeState(state)
+	sum, err := calc.Sum("sha256")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, expectedHashes["sha256"][fox], hex.EncodeToString(sum))
+}
+
+func TestSaveStateKeyed(t *testing.T) {
+	// the state of a keyed hash reveals the key, and is not saved
+	key := []byte("0123456789abcdef")
+	calc := NewCalculator(strings.NewReader(fox))
+	calc.Key(key)
+	_, _, err := calc.SumState("siphash-2-4")
+	assert.NotEqual(t, nil, err)
+
+	_, _, err = NewCalculator(strings.NewReader(fox)).SumState("siphash-2-4:key=" + hex.EncodeToString(key))
+	assert.NotEqual(t, nil, err)
+
+	h, _ := NewKeyedHash("siphash-2-4", key)
+	_, err = h.(encoding.BinaryMarshaler).MarshalBinary()
+	assert.NotEqual(t, nil, err)
+
+	// nor is a key accepted when resuming
+	_, state, err := NewCalculator(strings.NewReader(fox)).SumState("siphash-2-4")
+	assert.Equal(t, nil, err)
+	calc = NewCalculator(strings.NewReader(""))
+	calc.Key(key)
+	calc.ResumeState(state)
+	_, err = calc.Sum("siphash-2-4")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestResumeStateErrors(t *testing.T) {
+	_, state, err := NewCalculator(strings.NewReader(fox)).SumState("crc32")
+	assert.Equal(t, nil, err)
+
+	// aliases resolve to the same id
+	calc := NewCalculator(strings.NewReader(""))
+	calc.ResumeState(state)
+	_, err = calc.Sum("crc32-ieee")
+	assert.Equal(t, nil, err)
+
+	calc = NewCalculator(strings.NewReader(""))
+	calc.ResumeState(state)
+	_, err = calc.Sum("crc32c")
+	assert.NotEqual(t, nil, err)
+
+	calc = NewCalculator(strings.NewReader(""))
+	calc.ResumeState(state[:10])
+	_, err = calc.Sum("crc32")
+	assert.NotEqual(t, nil, err)
+
+	_, err = StateLength([]byte("nope"))
+	assert.NotEqual(t, nil, err)
+
+	// the crc state is only restored for the same model
+	b, _ := NewHash("crc-16/arc")
+	state, err = b.(encoding.BinaryMarshaler).MarshalBinary()
+	assert.Equal(t, nil, err)
+	for _, algo := range []string{"crc-16/arc:init=1", "crc-16/arc:refin=false", "crc-16/arc:refout=false", "crc-16/arc:xorout=1", "crc-16/arc:poly=1021"} {
+		h, _ := NewHash(algo)
+		assert.NotEqual(t, nil, h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state), algo)
+	}
+
+	// the whirlpool implementation can't save its state
+	_, _, err = NewCalculator(strings.NewReader(fox)).SumState("whirlpool")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,566 @@
+package gohash
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BitTorrent v1 info-hashes are the sha1 of the bencoded info dictionary, holding the sha1
+// of each piece, see https://www.bittorrent.org/beps/bep_0003.html. v2 info-hashes are the
+// sha256 of the info dictionary, holding the root of a sha256 merkle tree over the 16 KiB
+// blocks of each file, see https://www.bittorrent.org/beps/bep_0052.html
+
+const (
+	torrentBlockSize = 16 * 1024
+	// the default piece length is the smallest giving at most this many pieces
+	torrentMaxPieces      = 2048
+	torrentMaxPieceLength = 16 * 1024 * 1024
+)
+
+// TorrentFile is a file of a torrent
+type TorrentFile struct {
+	// slash separated path, relative to the torrent directory
+	Path   string
+	Length int64
+	// the v2 merkle root, nil for empty files
+	PiecesRoot []byte
+}
+
+// TorrentInfo holds the bencoded v1 and v2 info dictionaries of a file or directory
+type TorrentInfo struct {
+	Name        string
+	PieceLength int
+	Files       []TorrentFile
+	V1          []byte
+	V2          []byte
+}
+
+// InfoHashV1 returns the v1 info-hash, as used in magnet links with urn:btih
+func (t *TorrentInfo) InfoHashV1() []byte {
+	sum := sha1.Sum(t.V1)
+	return sum[:]
+}
+
+// InfoHashV2 returns the v2 info-hash, as used in magnet links with urn:btmh:1220
+func (t *TorrentInfo) InfoHashV2() []byte {
+	sum := sha256.Sum256(t.V2)
+	return sum[:]
+}
+
+// returns the smallest power of two which is `n` or more
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
+
+// returns the root of a sha256 merkle tree over the concatenated `hashes`, padded with
+// `pad` up to `leaves`, which is a power of two
+func merkleRoot(hashes []byte, leaves int, pad []byte) []byte {
+	level := make([][]byte, leaves)
+	for i := range level {
+		if i*sha256.Size < len(hashes) {
+			level[i] = hashes[i*sha256.Size : (i+1)*sha256.Size]
+		} else {
+			level[i] = pad
+		}
+	}
+	for len(level) > 1 {
+		next := make([][]byte, len(level)/2)
+		for i := range next {
+			h := sha256.New()
+			_, _ = h.Write(level[2*i])
+			_, _ = h.Write(level[2*i+1])
+			next[i] = h.Sum(nil)
+		}
+		level = next
+	}
+	return level[0]
+}
+
+// merkleFile calculates the v2 pieces root of a file written in blocks of 16 KiB,
+// keeping the hash of each piece rather than of each block
+type merkleFile struct {
+	blocksPerPiece int
+	// the hashes of the blocks of the current piece
+	blocks []byte
+	// the hashes of the previous pieces
+	layer []byte
+}
+
+func newMerkleFile(pieceLength int) *merkleFile {
+	return &merkleFile{blocksPerPiece: pieceLength / torrentBlockSize}
+}
+
+func (m *merkleFile) writeBlock(block []byte) {
+	sum := sha256.Sum256(block)
+	m.blocks = append(m.blocks, sum[:]...)
+	if len(m.blocks) == m.blocksPerPiece*sha256.Size {
+		m.layer = append(m.layer, merkleRoot(m.blocks, m.blocksPerPiece, make([]byte, sha256.Size))...)
+		m.blocks = m.blocks[:0]
+	}
+}
+
+func (m *merkleFile) root() []byte {
+	zero := make([]byte, sha256.Size)
+	if len(m.layer) == 0 {
+		if len(m.blocks) == 0 {
+			return nil
+		}
+		// files smaller than a piece are padded to a power of two blocks
+		return merkleRoot(m.blocks, nextPowerOfTwo(len(m.blocks)/sha256.Size), zero)
+	}
+	layer := m.layer
+	if len(m.blocks) > 0 {
+		layer = append(layer, merkleRoot(m.blocks, m.blocksPerPiece, zero)...)
+	}
+	pad := merkleRoot(nil, m.blocksPerPiece, zero)
+	return merkleRoot(layer, nextPowerOfTwo(len(layer)/sha256.Size), pad)
+}
+
+// piecesHash calculates the v1 pieces, the sha1 of each piece over the concatenated files
+type piecesHash struct {
+	pieceLength int
+	piece       hash.Hash
+	n           int
+	pieces      []byte
+}
+
+func newPiecesHash(pieceLength int) *piecesHash {
+	return &piecesHash{pieceLength: pieceLength, piece: sha1.New()}
+}
+
+func (h *piecesHash) write(p []byte) {
+	for len(p) > 0 {
+		c := len(p)
+		if c > h.pieceLength-h.n {
+			c = h.pieceLength - h.n
+		}
+		_, _ = h.piece.Write(p[:c])
+		h.n += c
+		p = p[c:]
+		if h.n == h.pieceLength {
+			h.pieces = h.piece.Sum(h.pieces)
+			h.piece.Reset()
+			h.n = 0
+		}
+	}
+}
+
+// returns the pieces, including the last partial piece
+func (h *piecesHash) sum() []byte {
+	if h.n > 0 {
+		return h.piece.Sum(h.pieces)
+	}
+	return h.pieces
+}
+
+// returns the default piece length for `size` bytes
+func defaultPieceLength(size int64) int {
+	pieceLength := torrentBlockSize
+	for (size+int64(pieceLength)-1)/int64(pieceLength) > torrentMaxPieces && pieceLength < torrentMaxPieceLength {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// lists the regular files of `path` in lexical order, relative to `path`
+func torrentFiles(path string) ([]TorrentFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []TorrentFile{{Path: fi.Name(), Length: fi.Size()}}, nil
+	}
+	var files []TorrentFile
+	err = filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, TorrentFile{Path: filepath.ToSlash(rel), Length: fi.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files in %s", path)
+	}
+	return files, nil
+}
+
+// NewTorrentInfo reads the file or directory `path` and returns its v1 and v2 info
+// dictionaries. `pieceLength` is a power of two of 16 KiB or more, or 0 for the default
+func NewTorrentInfo(path string, pieceLength int) (*TorrentInfo, error) {
+	files, err := torrentFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	single := !fi.IsDir()
+	if pieceLength == 0 {
+		size := int64(0)
+		for _, f := range files {
+			size += f.Length
+		}
+		pieceLength = defaultPieceLength(size)
+	}
+	if pieceLength < torrentBlockSize || pieceLength&(pieceLength-1) != 0 {
+		return nil, fmt.Errorf("piece length must be a power of two of 16 KiB or more, is %d", pieceLength)
+	}
+
+	pieces := newPiecesHash(pieceLength)
+	block := make([]byte, torrentBlockSize)
+	for i := range files {
+		name := path
+		if !single {
+			name = filepath.Join(path, filepath.FromSlash(files[i].Path))
+		}
+		m := newMerkleFile(pieceLength)
+		n, err := readBlocks(name, block, func(b []byte) {
+			pieces.write(b)
+			m.writeBlock(b)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if n != files[i].Length {
+			return nil, fmt.Errorf("%s changed while reading", name)
+		}
+		files[i].PiecesRoot = m.root()
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &TorrentInfo{Name: filepath.Base(abs), PieceLength: pieceLength, Files: files}
+
+	v1 := map[string]interface{}{
+		"name":         t.Name,
+		"piece length": pieceLength,
+		"pieces":       pieces.sum(),
+	}
+	fileTree := map[string]interface{}{}
+	var v1Files []interface{}
+	for _, f := range files {
+		elems := strings.Split(f.Path, "/")
+		v1Path := make([]interface{}, len(elems))
+		node := fileTree
+		for i, elem := range elems {
+			v1Path[i] = elem
+			if _, ok := node[elem]; !ok {
+				node[elem] = map[string]interface{}{}
+			}
+			node = node[elem].(map[string]interface{})
+		}
+		leaf := map[string]interface{}{"length": f.Length}
+		if f.PiecesRoot != nil {
+			leaf["pieces root"] = f.PiecesRoot
+		}
+		node[""] = leaf
+		v1Files = append(v1Files, map[string]interface{}{"length": f.Length, "path": v1Path})
+	}
+	if single {
+		v1["length"] = files[0].Length
+	} else {
+		v1["files"] = v1Files
+	}
+	t.V1 = bencode(v1)
+	t.V2 = bencode(map[string]interface{}{
+		"file tree":    fileTree,
+		"meta version": 2,
+		"name":         t.Name,
+		"piece length": pieceLength,
+	})
+	return t, nil
+}
+
+// reads the file `name` in blocks the size of `buf`, calling `fn` for each, and returns
+// the number of bytes read
+func readBlocks(name string, buf []byte, fn func([]byte)) (int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	total := int64(0)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			fn(buf[:n])
+			total += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// a file listed in a torrent
+type torrentEntry struct {
+	path       []string
+	length     int64
+	piecesRoot []byte
+	// a BEP 47 padding file, which is not stored
+	padding bool
+}
+
+func torrentInt(v interface{}) (int64, bool) {
+	n, ok := v.(int64)
+	return n, ok && n >= 0
+}
+
+// validates a path element of a torrent, which must not leave the torrent directory
+func torrentPathElement(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok || s == "" || s == "." || s == ".." || strings.ContainsAny(s, "/\\\x00") {
+		return "", fmt.Errorf("invalid path in torrent: %q", v)
+	}
+	return s, nil
+}
+
+// returns the files of the v2 "file tree" `node`
+func torrentFileTree(node map[string]interface{}, path []string) ([]torrentEntry, error) {
+	keys := make([]string, 0, len(node))
+	for k := range node {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var res []torrentEntry
+	for _, k := range keys {
+		child, ok := node[k].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid file tree in torrent")
+		}
+		if k == "" {
+			length, ok := torrentInt(child["length"])
+			if !ok || len(path) == 0 {
+				return nil, fmt.Errorf("invalid file tree in torrent")
+			}
+			root, _ := child["pieces root"].(string)
+			if length > 0 && len(root) != sha256.Size {
+				return nil, fmt.Errorf("invalid pieces root in torrent")
+			}
+			res = append(res, torrentEntry{path: path, length: length, piecesRoot: []byte(root)})
+			continue
+		}
+		elem, err := torrentPathElement(k)
+		if err != nil {
+			return nil, err
+		}
+		files, err := torrentFileTree(child, append(path[:len(path):len(path)], elem))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, files...)
+	}
+	return res, nil
+}
+
+// returns the files of the v1 info dictionary `info`, and if it is a single file torrent
+func torrentV1Files(info map[string]interface{}) ([]torrentEntry, bool, error) {
+	if length, ok := torrentInt(info["length"]); ok {
+		return []torrentEntry{{length: length}}, true, nil
+	}
+	list, ok := info["files"].([]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("torrent has no length or files")
+	}
+	var res []torrentEntry
+	for _, v := range list {
+		f, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("invalid files in torrent")
+		}
+		length, ok := torrentInt(f["length"])
+		if !ok {
+			return nil, false, fmt.Errorf("invalid file length in torrent")
+		}
+		elems, ok := f["path"].([]interface{})
+		if !ok || len(elems) == 0 {
+			return nil, false, fmt.Errorf("invalid file path in torrent")
+		}
+		e := torrentEntry{length: length}
+		for _, elem := range elems {
+			s, err := torrentPathElement(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			e.path = append(e.path, s)
+		}
+		attr, _ := f["attr"].(string)
+		e.padding = strings.Contains(attr, "p")
+		res = append(res, e)
+	}
+	return res, false, nil
+}
+
+// VerifyTorrent verifies the file or directory `path` against the contents of the .torrent
+// file `torrent`, returning the paths of the files which are missing or differ. The v2
+// pieces roots are verified if present, otherwise the v1 pieces
+func VerifyTorrent(torrent []byte, path string) ([]string, error) {
+	v, _, err := bdecode(torrent)
+	if err != nil {
+		return nil, err
+	}
+	top, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("torrent is not a dictionary")
+	}
+	info, ok := top["info"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("torrent has no info dictionary")
+	}
+	name, err := torrentPathElement(info["name"])
+	if err != nil {
+		return nil, err
+	}
+	pieceLength, ok := torrentInt(info["piece length"])
+	if !ok || pieceLength == 0 {
+		return nil, fmt.Errorf("invalid piece length in torrent")
+	}
+
+	if tree, ok := info["file tree"].(map[string]interface{}); ok {
+		if pieceLength < torrentBlockSize || pieceLength&(pieceLength-1) != 0 {
+			return nil, fmt.Errorf("invalid piece length in torrent")
+		}
+		files, err := torrentFileTree(tree, nil)
+		if err != nil {
+			return nil, err
+		}
+		// a single file torrent has the file named as the torrent at the top
+		single := len(files) == 1 && len(files[0].path) == 1 && files[0].path[0] == name
+		return verifyTorrentV2(files, path, single, int(pieceLength))
+	}
+
+	pieces, ok := info["pieces"].(string)
+	if !ok || len(pieces)%sha1.Size != 0 {
+		return nil, fmt.Errorf("invalid pieces in torrent")
+	}
+	files, single, err := torrentV1Files(info)
+	if err != nil {
+		return nil, err
+	}
+	return verifyTorrentV1(files, path, single, int(pieceLength), []byte(pieces))
+}
+
+// returns the local path of torrent file `e`
+func (e torrentEntry) localPath(path string, single bool) string {
+	if single {
+		return path
+	}
+	return filepath.Join(append([]string{path}, e.path...)...)
+}
+
+func (e torrentEntry) String() string {
+	return strings.Join(e.path, "/")
+}
+
+func verifyTorrentV2(files []torrentEntry, path string, single bool, pieceLength int) ([]string, error) {
+	var failed []string
+	block := make([]byte, torrentBlockSize)
+	for _, f := range files {
+		m := newMerkleFile(pieceLength)
+		n, err := readBlocks(f.localPath(path, single), block, m.writeBlock)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err != nil || n != f.length || !bytes.Equal(m.root(), f.piecesRoot) {
+			failed = append(failed, f.String())
+		}
+	}
+	return failed, nil
+}
+
+func verifyTorrentV1(files []torrentEntry, path string, single bool, pieceLength int, expected []byte) ([]string, error) {
+	total := int64(0)
+	for _, f := range files {
+		total += f.length
+	}
+	if (total+int64(pieceLength)-1)/int64(pieceLength) != int64(len(expected)/sha1.Size) {
+		return nil, fmt.Errorf("torrent has %d pieces for %d bytes", len(expected)/sha1.Size, total)
+	}
+	if single {
+		files[0].path = []string{filepath.Base(path)}
+	}
+
+	pieces := newPiecesHash(pieceLength)
+	bad := make([]bool, len(files))
+	block := make([]byte, torrentBlockSize)
+	zero := make([]byte, torrentBlockSize)
+	for idx, f := range files {
+		written := int64(0)
+		if !f.padding {
+			n, err := readBlocks(f.localPath(path, single), block, func(b []byte) {
+				if written+int64(len(b)) > f.length {
+					b = b[:f.length-written]
+				}
+				pieces.write(b)
+				written += int64(len(b))
+			})
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			if err != nil || n != f.length {
+				bad[idx] = true
+			}
+		}
+		// padding files and missing data are zeros
+		for written < f.length {
+			c := f.length - written
+			if c > torrentBlockSize {
+				c = torrentBlockSize
+			}
+			pieces.write(zero[:c])
+			written += c
+		}
+	}
+
+	// the files overlapping a piece which differs are marked bad
+	sums := pieces.sum()
+	start := int64(0)
+	for idx, f := range files {
+		end := start + f.length
+		for p := start / int64(pieceLength); f.length > 0 && p*int64(pieceLength) < end; p++ {
+			i := p * sha1.Size
+			if !bytes.Equal(sums[i:i+sha1.Size], expected[i:i+sha1.Size]) {
+				bad[idx] = true
+			}
+		}
+		start = end
+	}
+
+	var failed []string
+	for idx, f := range files {
+		if bad[idx] && !f.padding {
+			failed = append(failed, f.String())
+		}
+	}
+	return failed, nil
+}
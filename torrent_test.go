@@ -0,0 +1,212 @@
+package gohash
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha256Sum(data ...[]byte) []byte {
+	h := sha256.New()
+	for _, b := range data {
+		_, _ = h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// returns the v2 pieces root of `data`, from the whole tree of 16 KiB blocks
+func naivePiecesRoot(data []byte) []byte {
+	var level [][]byte
+	for i := 0; i < len(data); i += torrentBlockSize {
+		end := i + torrentBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		level = append(level, sha256Sum(data[i:end]))
+	}
+	for len(level) < nextPowerOfTwo(len(level)) {
+		level = append(level, make([]byte, sha256.Size))
+	}
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, sha256Sum(level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func TestMerkleFile(t *testing.T) {
+	data := bytes.Repeat([]byte(fox), 11*torrentBlockSize/len(fox))
+	for _, pieceLength := range []int{torrentBlockSize, 2 * torrentBlockSize, 4 * torrentBlockSize, 16 * torrentBlockSize} {
+		for _, size := range []int{1, torrentBlockSize - 1, torrentBlockSize, 3 * torrentBlockSize, 4 * torrentBlockSize, 5*torrentBlockSize + 7, len(data)} {
+			m := newMerkleFile(pieceLength)
+			for i := 0; i < size; i += torrentBlockSize {
+				end := i + torrentBlockSize
+				if end > size {
+					end = size
+				}
+				m.writeBlock(data[i:end])
+			}
+			assert.Equal(t, naivePiecesRoot(data[:size]), m.root(), "piece length %d, size %d", pieceLength, size)
+		}
+	}
+	assert.Equal(t, []byte(nil), newMerkleFile(torrentBlockSize).root())
+}
+
+func TestDefaultPieceLength(t *testing.T) {
+	assert.Equal(t, 16*1024, defaultPieceLength(0))
+	assert.Equal(t, 16*1024, defaultPieceLength(2048*16*1024))
+	assert.Equal(t, 32*1024, defaultPieceLength(2048*16*1024+1))
+	assert.Equal(t, 16*1024*1024, defaultPieceLength(1<<50))
+}
+
+func TestTorrentInfoSingleFile(t *testing.T) {
+	data := bytes.Repeat([]byte(fox), 40000/len(fox)+1)[:40000]
+	name := filepath.Join(t.TempDir(), "fox.txt")
+	assert.Equal(t, nil, ioutil.WriteFile(name, data, 0644))
+
+	ti, err := NewTorrentInfo(name, 16384)
+	assert.Equal(t, nil, err)
+
+	pieces := string(sha1Sum(data[:16384])) + string(sha1Sum(data[16384:32768])) + string(sha1Sum(data[32768:]))
+	assert.Equal(t, "d6:lengthi40000e4:name7:fox.txt12:piece lengthi16384e6:pieces60:"+pieces+"e", string(ti.V1))
+
+	root := sha256Sum(sha256Sum(sha256Sum(data[:16384]), sha256Sum(data[16384:32768])), sha256Sum(sha256Sum(data[32768:]), make([]byte, 32)))
+	assert.Equal(t, "d9:file treed7:fox.txtd0:d6:lengthi40000e11:pieces root32:"+string(root)+"eee"+
+		"12:meta versioni2e4:name7:fox.txt12:piece lengthi16384ee", string(ti.V2))
+
+	assert.Equal(t, []TorrentFile{{Path: "fox.txt", Length: 40000, PiecesRoot: root}}, ti.Files)
+	v1 := sha1.Sum(ti.V1)
+	assert.Equal(t, v1[:], ti.InfoHashV1())
+	v2 := sha256.Sum256(ti.V2)
+	assert.Equal(t, v2[:], ti.InfoHashV2())
+
+	_, err = NewTorrentInfo(name, 1000)
+	assert.NotEqual(t, nil, err)
+}
+
+// creates a directory with files b/c.txt, a.txt and empty.txt
+func writeTorrentDir(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "release")
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(dir, "b"), 0755))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "b", "c.txt"), bytes.Repeat([]byte(fox), 1000), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte(fox), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "empty.txt"), nil, 0644))
+	return dir
+}
+
+func TestTorrentInfoDirectory(t *testing.T) {
+	dir := writeTorrentDir(t)
+	ti, err := NewTorrentInfo(dir, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "release", ti.Name)
+	assert.Equal(t, 16384, ti.PieceLength)
+
+	data := append([]byte(fox), bytes.Repeat([]byte(fox), 1000)...)
+	assert.Equal(t, []TorrentFile{
+		{Path: "a.txt", Length: 43, PiecesRoot: sha256Sum([]byte(fox))},
+		{Path: "b/c.txt", Length: 43000, PiecesRoot: naivePiecesRoot(data[43:])},
+		{Path: "empty.txt", Length: 0},
+	}, ti.Files)
+
+	v1, _, err := bdecode(ti.V1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"length": int64(43), "path": []interface{}{"a.txt"}},
+		map[string]interface{}{"length": int64(43000), "path": []interface{}{"b", "c.txt"}},
+		map[string]interface{}{"length": int64(0), "path": []interface{}{"empty.txt"}},
+	}, v1.(map[string]interface{})["files"])
+	assert.Equal(t, string(sha1Sum(data[:16384]))+string(sha1Sum(data[16384:32768]))+string(sha1Sum(data[32768:])),
+		v1.(map[string]interface{})["pieces"])
+
+	v2, _, err := bdecode(ti.V2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]interface{}{
+		"a.txt": map[string]interface{}{"": map[string]interface{}{"length": int64(43), "pieces root": string(sha256Sum([]byte(fox)))}},
+		"b": map[string]interface{}{
+			"c.txt": map[string]interface{}{"": map[string]interface{}{"length": int64(43000), "pieces root": string(naivePiecesRoot(data[43:]))}},
+		},
+		"empty.txt": map[string]interface{}{"": map[string]interface{}{"length": int64(0)}},
+	}, v2.(map[string]interface{})["file tree"])
+}
+
+func TestVerifyTorrent(t *testing.T) {
+	dir := writeTorrentDir(t)
+	ti, err := NewTorrentInfo(dir, 0)
+	assert.Equal(t, nil, err)
+	torrentV1 := append(append([]byte("d4:info"), ti.V1...), 'e')
+	torrentV2 := append(append([]byte("d4:info"), ti.V2...), 'e')
+
+	for _, torrent := range [][]byte{torrentV1, torrentV2} {
+		failed, err := VerifyTorrent(torrent, dir)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string(nil), failed)
+	}
+
+	// a.txt shares its piece with c.txt, so v1 can not tell them apart
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("The quick brown fox jumps over the lazy cat"), 0644))
+	assert.Equal(t, nil, os.Remove(filepath.Join(dir, "empty.txt")))
+	failed, err := VerifyTorrent(torrentV1, dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"a.txt", "b/c.txt", "empty.txt"}, failed)
+	failed, err = VerifyTorrent(torrentV2, dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"a.txt", "empty.txt"}, failed)
+
+	// the last piece of c.txt differs
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte(fox), 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "empty.txt"), nil, 0644))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "b", "c.txt"), bytes.Repeat([]byte(fox), 999), 0644))
+	failed, err = VerifyTorrent(torrentV1, dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"b/c.txt"}, failed)
+
+	// a single file torrent is verified against the file
+	name := filepath.Join(dir, "a.txt")
+	ti, err = NewTorrentInfo(name, 0)
+	assert.Equal(t, nil, err)
+	for _, info := range [][]byte{ti.V1, ti.V2} {
+		failed, err := VerifyTorrent(append(append([]byte("d4:info"), info...), 'e'), name)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string(nil), failed)
+	}
+
+	_, err = VerifyTorrent([]byte("d4:infod4:name2:..12:piece lengthi16384e6:lengthi1eee"), dir)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestVerifyTorrentPadding(t *testing.T) {
+	// BEP 47 padding files align c.txt to a piece, and are not stored
+	dir := writeTorrentDir(t)
+	data := append([]byte(fox), make([]byte, 16384-43)...)
+	data = append(data, bytes.Repeat([]byte(fox), 1000)...)
+	var pieces []byte
+	for i := 0; i < len(data); i += 16384 {
+		end := i + 16384
+		if end > len(data) {
+			end = len(data)
+		}
+		pieces = append(pieces, sha1Sum(data[i:end])...)
+	}
+	info := bencode(map[string]interface{}{
+		"name":         "release",
+		"piece length": 16384,
+		"pieces":       pieces,
+		"files": []interface{}{
+			map[string]interface{}{"length": 43, "path": []interface{}{"a.txt"}},
+			map[string]interface{}{"length": 16384 - 43, "path": []interface{}{".pad", "16341"}, "attr": "p"},
+			map[string]interface{}{"length": 43000, "path": []interface{}{"b", "c.txt"}},
+		},
+	})
+	failed, err := VerifyTorrent(append(append([]byte("d4:info"), info...), 'e'), dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string(nil), failed)
+}
@@ -30,6 +30,15 @@ func isStringInSlice(a string, list []string) bool {
 	return false
 }
 
+func isIntInSlice(a int, list []int) bool {
+	for _, b := range list {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
 func isByteInSlice(a byte, list []byte) bool {
 	for _, b := range list {
 		if b == a {